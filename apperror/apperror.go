@@ -0,0 +1,91 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of application errors. Check them with errors.Is.
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrValidation = errors.New("validation failed")
+	ErrForbidden  = errors.New("forbidden")
+	ErrTimeout    = errors.New("timeout")
+	ErrCanceled   = errors.New("canceled")
+
+	ErrPreconditionFailed   = errors.New("precondition failed")
+	ErrPreconditionRequired = errors.New("precondition required")
+)
+
+// FieldError describes why a single input field was rejected.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Error is an error of a known Kind with a client-safe Message. The
+// underlying cause, if any, is kept in Err.
+type Error struct {
+	Kind    error
+	Message string
+	Err     error
+	Fields  []FieldError
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+
+	return e.Message
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	e.Fields = append(e.Fields, fields...)
+	return e
+}
+
+func New(kind error, err error, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+func NotFound(err error, format string, args ...interface{}) *Error {
+	return New(ErrNotFound, err, format, args...)
+}
+
+func Conflict(err error, format string, args ...interface{}) *Error {
+	return New(ErrConflict, err, format, args...)
+}
+
+func Validation(err error, format string, args ...interface{}) *Error {
+	return New(ErrValidation, err, format, args...)
+}
+
+func Forbidden(err error, format string, args ...interface{}) *Error {
+	return New(ErrForbidden, err, format, args...)
+}
+
+func Timeout(err error, format string, args ...interface{}) *Error {
+	return New(ErrTimeout, err, format, args...)
+}
+
+func Canceled(err error, format string, args ...interface{}) *Error {
+	return New(ErrCanceled, err, format, args...)
+}
+
+func PreconditionFailed(err error, format string, args ...interface{}) *Error {
+	return New(ErrPreconditionFailed, err, format, args...)
+}
+
+func PreconditionRequired(err error, format string, args ...interface{}) *Error {
+	return New(ErrPreconditionRequired, err, format, args...)
+}
@@ -0,0 +1,29 @@
+package apperror
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestErrorKinds(t *testing.T) {
+	err := NotFound(sql.ErrNoRows, "user %s not found", "01JW4MH8S671QVVGD0NYY1XWAP")
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NotErrorIs(t, err, ErrConflict)
+	assert.Equal(t, "user 01JW4MH8S671QVVGD0NYY1XWAP not found", err.Message)
+	assert.Equal(t, "user 01JW4MH8S671QVVGD0NYY1XWAP not found: sql: no rows in result set", err.Error())
+}
+
+func TestErrorKindsWrapped(t *testing.T) {
+	err := fmt.Errorf("%w: bad op", Validation(nil, "invalid patch document"))
+
+	var appErr *Error
+	assert.True(t, errors.As(err, &appErr))
+	assert.ErrorIs(t, err, ErrValidation)
+	assert.Equal(t, "invalid patch document", appErr.Message)
+}
@@ -34,14 +34,18 @@ func parseFields(args []string) (string, string) {
 	structFields = append(structFields, "    ID string `db:\"id\" json:\"id\"`")
 	fields = append(fields, "\"id\"")
 	for _, field := range args {
-		parts := strings.Split(field, ":")
-		if len(parts) != 2 {
-			log.Fatalf("Campo inválido: %s. Use Nome:Tipo", field)
+		parts := strings.SplitN(field, ":", 3)
+		if len(parts) < 2 {
+			log.Fatalf("Campo inválido: %s. Use Nome:Tipo[:Regras]", field)
 		}
 		name := parts[0]
 		typ := parts[1]
 		dbTag := strings.ToLower(name)
-		fieldLine := fmt.Sprintf("    %s %s `db:\"%s\" json:\"%s\"`", name, typ, dbTag, dbTag)
+		fieldLine := fmt.Sprintf("    %s %s `db:\"%s\" json:\"%s\"", name, typ, dbTag, dbTag)
+		if len(parts) == 3 && parts[2] != "" {
+			fieldLine += fmt.Sprintf(" validate:\"%s\"", parts[2])
+		}
+		fieldLine += "`"
 		structFields = append(structFields, fieldLine)
 		fields = append(fields, "\""+dbTag+"\"")
 	}
@@ -57,7 +61,7 @@ func parseFields(args []string) (string, string) {
 
 func main() {
 	if len(os.Args) < 2 {
-		log.Fatal("Você precisa passar o nome do domínio. Ex: go run main.go car [Nome:string Idade:int]")
+		log.Fatal("Você precisa passar o nome do domínio. Ex: go run main.go car [Nome:string:required Idade:int:gte=0]")
 	}
 
 	domain := strings.ToLower(os.Args[1])
@@ -92,7 +96,7 @@ func main() {
 		registryContent = []byte(strings.Replace(string(registryContent), "import (", "import "+importLine, 1))
 	}
 
-	registerLine := fmt.Sprintf("\tRegisterGenericResource[model.%s](r, db, \"%s\", model.%sFields)", structName, domain, structName)
+	registerLine := fmt.Sprintf("\tRegisterGenericResource[model.%s](r, uow, cfg, \"%s\", model.%sFields)", structName, domain, structName)
 	if strings.Contains(string(registryContent), registerLine) {
 		fmt.Println("ℹ️  Já registrado em registry.go")
 	} else {
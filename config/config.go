@@ -0,0 +1,345 @@
+package config
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"api_boilerplate/dialect"
+	"api_boilerplate/pagination"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// FileEnv names the environment variable holding the optional config file.
+const FileEnv = "CONFIG_FILE"
+
+type Config struct {
+	Server    ServerConfig              `yaml:"server" toml:"server"`
+	Database  DatabaseConfig            `yaml:"database" toml:"database"`
+	Health    HealthConfig              `yaml:"health" toml:"health"`
+	LogLevel  string                    `yaml:"log_level" toml:"log_level"`
+	Resources map[string]ResourceConfig `yaml:"resources" toml:"resources"`
+}
+
+type ServerConfig struct {
+	Addr         string   `yaml:"addr" toml:"addr"`
+	GinMode      string   `yaml:"gin_mode" toml:"gin_mode"`
+	ReadTimeout  Duration `yaml:"read_timeout" toml:"read_timeout"`
+	WriteTimeout Duration `yaml:"write_timeout" toml:"write_timeout"`
+	IdleTimeout  Duration `yaml:"idle_timeout" toml:"idle_timeout"`
+	// DrainTimeout bounds how long shutdown waits for in-flight requests.
+	DrainTimeout Duration `yaml:"drain_timeout" toml:"drain_timeout"`
+	// AdminToken is the bearer token of admin endpoints; empty disables them.
+	AdminToken string `yaml:"admin_token" toml:"admin_token"`
+}
+
+type DatabaseConfig struct {
+	Dialect         string   `yaml:"dialect" toml:"dialect"`
+	DSN             string   `yaml:"dsn" toml:"dsn"`
+	MaxOpenConns    int      `yaml:"max_open_conns" toml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns" toml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime" toml:"conn_max_lifetime"`
+	// QueryTimeout bounds each statement unless a resource overrides it.
+	QueryTimeout Duration `yaml:"query_timeout" toml:"query_timeout"`
+	// IsolationLevel of transactions; empty uses the database default.
+	IsolationLevel string `yaml:"isolation_level" toml:"isolation_level"`
+}
+
+type HealthConfig struct {
+	// Timeout bounds each readiness check.
+	Timeout Duration `yaml:"timeout" toml:"timeout"`
+}
+
+// ResourceConfig holds the options of a single resource, keyed by its path.
+type ResourceConfig struct {
+	DefaultLimit int      `yaml:"default_limit" toml:"default_limit"`
+	MaxLimit     int      `yaml:"max_limit" toml:"max_limit"`
+	QueryTimeout Duration `yaml:"query_timeout" toml:"query_timeout"`
+	MaxBulkItems int      `yaml:"max_bulk_items" toml:"max_bulk_items"`
+	// Retention is how long soft-deleted rows are kept before a purge.
+	Retention Duration `yaml:"retention" toml:"retention"`
+	// RequireIfMatch rejects writes without If-Match on versioned resources.
+	RequireIfMatch bool `yaml:"require_if_match" toml:"require_if_match"`
+}
+
+// DefaultRetention applies to resources without a retention.
+const DefaultRetention = 30 * 24 * time.Hour
+
+// Duration reads values such as "3m" or "1h30m" from config files.
+type Duration time.Duration
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// Default returns the settings used when nothing else is configured.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Addr:         "0.0.0.0:3030",
+			GinMode:      gin.ReleaseMode,
+			ReadTimeout:  Duration(15 * time.Second),
+			WriteTimeout: Duration(30 * time.Second),
+			IdleTimeout:  Duration(60 * time.Second),
+			DrainTimeout: Duration(30 * time.Second),
+		},
+		Database: DatabaseConfig{
+			Dialect:         "mysql",
+			DSN:             "root:root@/api_boilerplate",
+			MaxOpenConns:    10,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: Duration(3 * time.Minute),
+			QueryTimeout:    Duration(10 * time.Second),
+		},
+		Health:    HealthConfig{Timeout: Duration(2 * time.Second)},
+		LogLevel:  "info",
+		Resources: map[string]ResourceConfig{},
+	}
+}
+
+// Load reads the file named by CONFIG_FILE, if any, then applies
+// environment overrides and validates the result.
+func Load() (*Config, error) {
+	return load(os.Getenv(FileEnv), os.LookupEnv)
+}
+
+func load(path string, lookup func(string) (string, bool)) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		if err := cfg.readFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.applyEnv(lookup); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+func (c *Config) readFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, c)
+	case ".toml":
+		err = toml.Unmarshal(data, c)
+	default:
+		return fmt.Errorf("unsupported config file extension %q", ext)
+	}
+
+	if err != nil {
+		return fmt.Errorf("parsing config file %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	strs := map[string]*string{
+		"HTTP_ADDR":          &c.Server.Addr,
+		"GIN_MODE":           &c.Server.GinMode,
+		"DB_DIALECT":         &c.Database.Dialect,
+		"DB_DSN":             &c.Database.DSN,
+		"DB_ISOLATION_LEVEL": &c.Database.IsolationLevel,
+		"LOG_LEVEL":          &c.LogLevel,
+		"ADMIN_TOKEN":        &c.Server.AdminToken,
+	}
+	for key, target := range strs {
+		if value, ok := lookup(key); ok && value != "" {
+			*target = value
+		}
+	}
+
+	ints := map[string]*int{
+		"DB_MAX_OPEN_CONNS": &c.Database.MaxOpenConns,
+		"DB_MAX_IDLE_CONNS": &c.Database.MaxIdleConns,
+	}
+	for key, target := range ints {
+		if value, ok := lookup(key); ok && value != "" {
+			parsed, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("%s must be an integer", key)
+			}
+			*target = parsed
+		}
+	}
+
+	durations := map[string]*Duration{
+		"HTTP_READ_TIMEOUT":    &c.Server.ReadTimeout,
+		"HTTP_WRITE_TIMEOUT":   &c.Server.WriteTimeout,
+		"HTTP_IDLE_TIMEOUT":    &c.Server.IdleTimeout,
+		"HTTP_DRAIN_TIMEOUT":   &c.Server.DrainTimeout,
+		"DB_CONN_MAX_LIFETIME": &c.Database.ConnMaxLifetime,
+		"DB_QUERY_TIMEOUT":     &c.Database.QueryTimeout,
+		"HEALTH_TIMEOUT":       &c.Health.Timeout,
+	}
+	for key, target := range durations {
+		if value, ok := lookup(key); ok && value != "" {
+			if err := target.UnmarshalText([]byte(value)); err != nil {
+				return fmt.Errorf("%s must be a duration such as 3m", key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Validate reports every invalid setting at once.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		errs = append(errs, fmt.Errorf("server.addr: %w", err))
+	}
+
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 || c.Server.DrainTimeout < 0 {
+		errs = append(errs, errors.New("server timeouts must not be negative"))
+	}
+
+	switch c.Server.GinMode {
+	case gin.DebugMode, gin.ReleaseMode, gin.TestMode:
+	default:
+		errs = append(errs, errors.New("server.gin_mode must be one of debug, release, test"))
+	}
+
+	if _, err := dialect.Driver(c.Database.Dialect); err != nil {
+		errs = append(errs, fmt.Errorf("database.dialect: %w", err))
+	}
+
+	if c.Database.DSN == "" {
+		errs = append(errs, errors.New("database.dsn is required"))
+	}
+
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		errs = append(errs, errors.New("database pool sizes must not be negative"))
+	}
+
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		errs = append(errs, errors.New("database.max_idle_conns must not exceed database.max_open_conns"))
+	}
+
+	if c.Database.ConnMaxLifetime < 0 {
+		errs = append(errs, errors.New("database.conn_max_lifetime must not be negative"))
+	}
+
+	if c.Database.QueryTimeout < 0 {
+		errs = append(errs, errors.New("database.query_timeout must not be negative"))
+	}
+
+	if _, err := c.Database.Isolation(); err != nil {
+		errs = append(errs, fmt.Errorf("database.isolation_level: %w", err))
+	}
+
+	if c.Health.Timeout <= 0 {
+		errs = append(errs, errors.New("health.timeout must be positive"))
+	}
+
+	if _, err := c.Level(); err != nil {
+		errs = append(errs, fmt.Errorf("log_level: %w", err))
+	}
+
+	for name, resource := range c.Resources {
+		limits := resource.Limits()
+		if resource.DefaultLimit < 0 || resource.MaxLimit < 0 || limits.Default > limits.Max {
+			errs = append(errs, fmt.Errorf("resources.%s: limits must satisfy 1 <= default_limit <= max_limit", name))
+		}
+
+		if resource.QueryTimeout < 0 {
+			errs = append(errs, fmt.Errorf("resources.%s: query_timeout must not be negative", name))
+		}
+
+		if resource.MaxBulkItems < 0 {
+			errs = append(errs, fmt.Errorf("resources.%s: max_bulk_items must not be negative", name))
+		}
+
+		if resource.Retention < 0 {
+			errs = append(errs, fmt.Errorf("resources.%s: retention must not be negative", name))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+var isolationLevels = map[string]sql.IsolationLevel{
+	"":                 sql.LevelDefault,
+	"read_uncommitted": sql.LevelReadUncommitted,
+	"read_committed":   sql.LevelReadCommitted,
+	"repeatable_read":  sql.LevelRepeatableRead,
+	"serializable":     sql.LevelSerializable,
+}
+
+// Isolation parses IsolationLevel.
+func (d DatabaseConfig) Isolation() (sql.IsolationLevel, error) {
+	level, ok := isolationLevels[d.IsolationLevel]
+	if !ok {
+		return level, fmt.Errorf("unknown isolation level %q", d.IsolationLevel)
+	}
+
+	return level, nil
+}
+
+// Level parses LogLevel (debug, info, warn or error).
+func (c *Config) Level() (slog.Level, error) {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(c.LogLevel))
+	return level, err
+}
+
+// Resource returns the options of the resource served at path, with the
+// database query timeout and DefaultRetention as fallbacks.
+func (c *Config) Resource(path string) ResourceConfig {
+	resource := c.Resources[path]
+	if resource.QueryTimeout == 0 {
+		resource.QueryTimeout = c.Database.QueryTimeout
+	}
+
+	if resource.Retention == 0 {
+		resource.Retention = Duration(DefaultRetention)
+	}
+
+	return resource
+}
+
+// Limits falls back to the global pagination limits for unset values.
+func (r ResourceConfig) Limits() pagination.Limits {
+	limits := pagination.DefaultLimits
+
+	if r.MaxLimit > 0 {
+		limits.Max = r.MaxLimit
+	}
+
+	if r.DefaultLimit > 0 {
+		limits.Default = r.DefaultLimit
+	} else if limits.Default > limits.Max {
+		limits.Default = limits.Max
+	}
+
+	return limits
+}
@@ -0,0 +1,144 @@
+package config
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func env(values map[string]string) func(string) (string, bool) {
+	return func(key string) (string, bool) {
+		value, ok := values[key]
+		return value, ok
+	}
+}
+
+func writeFile(t *testing.T, name string, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := load("", env(nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestLoadYAML(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  addr: ":8080"
+database:
+  dialect: postgres
+  dsn: postgres://localhost/app
+  max_open_conns: 20
+  conn_max_lifetime: 5m
+resources:
+  product:
+    max_limit: 50
+    query_timeout: 2s
+    retention: 168h
+    require_if_match: true
+`)
+
+	cfg, err := load(path, env(nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, ":8080", cfg.Server.Addr)
+	assert.Equal(t, "postgres", cfg.Database.Dialect)
+	assert.Equal(t, 20, cfg.Database.MaxOpenConns)
+	assert.Equal(t, 10, cfg.Database.MaxIdleConns)
+	assert.Equal(t, Duration(5*time.Minute), cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, 50, cfg.Resource("product").Limits().Max)
+	assert.Equal(t, 20, cfg.Resource("product").Limits().Default)
+	assert.Equal(t, Duration(2*time.Second), cfg.Resource("product").QueryTimeout)
+	assert.Equal(t, Duration(10*time.Second), cfg.Resource("user").QueryTimeout)
+	assert.Equal(t, Duration(7*24*time.Hour), cfg.Resource("product").Retention)
+	assert.Equal(t, Duration(DefaultRetention), cfg.Resource("user").Retention)
+	assert.True(t, cfg.Resource("product").RequireIfMatch)
+}
+
+func TestLoadTOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+log_level = "debug"
+
+[database]
+dialect = "sqlite"
+dsn = "file:api.db"
+conn_max_lifetime = "1m"
+
+[resources.user]
+default_limit = 5
+`)
+
+	cfg, err := load(path, env(nil))
+
+	require.NoError(t, err)
+	assert.Equal(t, "sqlite", cfg.Database.Dialect)
+	assert.Equal(t, Duration(time.Minute), cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, 5, cfg.Resource("user").Limits().Default)
+}
+
+func TestLoadEnvOverridesFile(t *testing.T) {
+	path := writeFile(t, "config.yml", "server:\n  addr: \":8080\"\n")
+
+	cfg, err := load(path, env(map[string]string{
+		"HTTP_ADDR":            ":9090",
+		"DB_DSN":               "other",
+		"DB_MAX_OPEN_CONNS":    "30",
+		"DB_CONN_MAX_LIFETIME": "10s",
+		"HTTP_DRAIN_TIMEOUT":   "5s",
+		"DB_ISOLATION_LEVEL":   "serializable",
+	}))
+
+	require.NoError(t, err)
+	assert.Equal(t, ":9090", cfg.Server.Addr)
+	assert.Equal(t, "other", cfg.Database.DSN)
+	assert.Equal(t, 30, cfg.Database.MaxOpenConns)
+	assert.Equal(t, Duration(10*time.Second), cfg.Database.ConnMaxLifetime)
+	assert.Equal(t, Duration(5*time.Second), cfg.Server.DrainTimeout)
+
+	level, err := cfg.Database.Isolation()
+	assert.NoError(t, err)
+	assert.Equal(t, sql.LevelSerializable, level)
+}
+
+func TestLoadInvalid(t *testing.T) {
+	_, err := load("", env(map[string]string{"DB_MAX_OPEN_CONNS": "ten"}))
+	assert.EqualError(t, err, "DB_MAX_OPEN_CONNS must be an integer")
+
+	_, err = load(writeFile(t, "config.json", "{}"), env(nil))
+	assert.Error(t, err)
+
+	_, err = load("", env(map[string]string{
+		"HTTP_ADDR":          "3030",
+		"DB_DIALECT":         "oracle",
+		"GIN_MODE":           "prod",
+		"LOG_LEVEL":          "loud",
+		"DB_ISOLATION_LEVEL": "snapshot",
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "server.addr")
+	assert.Contains(t, err.Error(), "database.dialect")
+	assert.Contains(t, err.Error(), "server.gin_mode")
+	assert.Contains(t, err.Error(), "log_level")
+	assert.Contains(t, err.Error(), "database.isolation_level")
+}
+
+func TestValidateResourceLimits(t *testing.T) {
+	cfg := Default()
+	cfg.Resources["product"] = ResourceConfig{DefaultLimit: 30, MaxLimit: 10}
+
+	assert.ErrorContains(t, cfg.Validate(), "resources.product")
+
+	cfg.Resources["product"] = ResourceConfig{MaxLimit: 10}
+	assert.NoError(t, cfg.Validate())
+	assert.Equal(t, 10, cfg.Resource("product").Limits().Default)
+}
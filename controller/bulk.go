@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"log/slog"
+	"net/http"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/problem"
+	"api_boilerplate/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBulkItems is the largest bulk request accepted when MaxBulkItems
+// is not set.
+const DefaultMaxBulkItems = 1000
+
+type bulkItem struct {
+	Index  int              `json:"index"`
+	Status int              `json:"status"`
+	ID     string           `json:"id,omitempty"`
+	Item   interface{}      `json:"item,omitempty"`
+	Error  *problem.Problem `json:"error,omitempty"`
+}
+
+type bulkResponse struct {
+	Results   []bulkItem `json:"results"`
+	Succeeded int        `json:"succeeded"`
+	Failed    int        `json:"failed"`
+}
+
+// bindBulk reads the mode query parameter and the JSON array body.
+func (c *GenericController[T]) bindBulk(ctx *gin.Context, items interface{}, count func() int) (service.BulkMode, bool) {
+	mode, err := service.ParseBulkMode(ctx.Query("mode"))
+	if err != nil {
+		problem.Respond(ctx, err)
+		return "", false
+	}
+
+	if err := ctx.ShouldBindJSON(items); err != nil {
+		problem.Respond(ctx, apperror.Validation(err, "request body must be a JSON array"))
+		return "", false
+	}
+
+	maxItems := c.MaxBulkItems
+	if maxItems <= 0 {
+		maxItems = DefaultMaxBulkItems
+	}
+
+	if n := count(); n == 0 || n > maxItems {
+		problem.Respond(ctx, apperror.Validation(nil, "bulk requests must have between 1 and %d items", maxItems))
+		return "", false
+	}
+
+	return mode, true
+}
+
+// writeBulk renders the per-item results. status is used when every item
+// succeeded; otherwise the response is 207 Multi-Status.
+func writeBulk[T any](ctx *gin.Context, status int, itemStatus int, results []service.BulkResult[T], err error) {
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	response := bulkResponse{Results: make([]bulkItem, len(results))}
+	for i, result := range results {
+		item := bulkItem{Index: result.Index, Status: itemStatus, ID: result.ID}
+
+		if result.Err != nil {
+			p := problem.FromError(result.Err)
+			if p.Status == http.StatusInternalServerError {
+				slog.Error("bulk item failed", "path", ctx.Request.URL.Path, "index", result.Index, "error", result.Err)
+			}
+
+			item.Status = p.Status
+			item.Error = &p
+			response.Failed++
+		} else {
+			if result.Item != nil {
+				item.Item = result.Item
+			}
+			response.Succeeded++
+		}
+
+		response.Results[i] = item
+	}
+
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+
+	ctx.JSON(status, response)
+}
+
+func (c *GenericController[T]) CreateMany(ctx *gin.Context) {
+	var items []T
+	mode, ok := c.bindBulk(ctx, &items, func() int { return len(items) })
+	if !ok {
+		return
+	}
+
+	results, err := c.Service.CreateMany(ctx.Request.Context(), items, mode)
+	writeBulk(ctx, http.StatusCreated, http.StatusCreated, results, err)
+}
+
+func (c *GenericController[T]) PatchMany(ctx *gin.Context) {
+	var patches []service.BulkPatch
+	mode, ok := c.bindBulk(ctx, &patches, func() int { return len(patches) })
+	if !ok {
+		return
+	}
+
+	results, err := c.Service.PatchMany(ctx.Request.Context(), patches, mode)
+	writeBulk(ctx, http.StatusOK, http.StatusOK, results, err)
+}
+
+func (c *GenericController[T]) DeleteMany(ctx *gin.Context) {
+	var ids []string
+	mode, ok := c.bindBulk(ctx, &ids, func() int { return len(ids) })
+	if !ok {
+		return
+	}
+
+	results, err := c.Service.DeleteMany(ctx.Request.Context(), ids, mode)
+	writeBulk(ctx, http.StatusOK, http.StatusNoContent, results, err)
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenericController_CreateMany(t *testing.T) {
+	svc := &MockService[TestModel]{
+		CreateManyFn: func(items []TestModel, mode service.BulkMode) ([]service.BulkResult[TestModel], error) {
+			assert.Equal(t, service.BulkAtomic, mode)
+			results := make([]service.BulkResult[TestModel], len(items))
+			for i := range items {
+				items[i].ID = "01JW4MH8S671QVVGD0NYY1XWAP"
+				results[i] = service.BulkResult[TestModel]{Index: i, ID: items[i].ID, Item: &items[i]}
+			}
+			return results, nil
+		},
+	}
+	router := setupRouter(NewGenericController(svc, testFields))
+
+	req, _ := http.NewRequest("POST", "/test/bulk", bytes.NewBufferString(`[{"name":"a"},{"name":"b"}]`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, 201, resp.Code)
+
+	var body bulkResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, 2, body.Succeeded)
+	assert.Zero(t, body.Failed)
+	assert.Equal(t, 201, body.Results[1].Status)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", body.Results[1].ID)
+}
+
+func TestGenericController_CreateManyAtomicFailure(t *testing.T) {
+	svc := &MockService[TestModel]{
+		CreateManyFn: func(items []TestModel, mode service.BulkMode) ([]service.BulkResult[TestModel], error) {
+			return nil, apperror.Conflict(nil, "item 1: name already exists")
+		},
+	}
+	router := setupRouter(NewGenericController(svc, testFields))
+
+	req, _ := http.NewRequest("POST", "/test/bulk", bytes.NewBufferString(`[{"name":"a"},{"name":"a"}]`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 409, resp.Code)
+	assert.Contains(t, resp.Body.String(), "item 1")
+}
+
+func TestGenericController_DeleteManyPartial(t *testing.T) {
+	svc := &MockService[TestModel]{
+		DeleteManyFn: func(ids []string, mode service.BulkMode) ([]service.BulkResult[TestModel], error) {
+			assert.Equal(t, service.BulkPartial, mode)
+			return []service.BulkResult[TestModel]{
+				{Index: 0, ID: ids[0]},
+				{Index: 1, ID: ids[1], Err: apperror.NotFound(nil, "not found")},
+			}, nil
+		},
+	}
+	router := setupRouter(NewGenericController(svc, testFields))
+
+	req, _ := http.NewRequest("DELETE", "/test/bulk?mode=partial", bytes.NewBufferString(`["a","b"]`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	require.Equal(t, http.StatusMultiStatus, resp.Code)
+
+	var body bulkResponse
+	require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &body))
+	assert.Equal(t, 1, body.Succeeded)
+	assert.Equal(t, 1, body.Failed)
+	assert.Equal(t, 204, body.Results[0].Status)
+	assert.Equal(t, 404, body.Results[1].Status)
+	assert.Equal(t, "/problems/not-found", body.Results[1].Error.Type)
+}
+
+func TestGenericController_BulkInvalidRequests(t *testing.T) {
+	ctrl := NewGenericController(&MockService[TestModel]{}, testFields)
+	ctrl.MaxBulkItems = 2
+	router := setupRouter(ctrl)
+
+	requests := []struct {
+		url  string
+		body string
+	}{
+		{"/test/bulk", `{"name":"a"}`},
+		{"/test/bulk", `[]`},
+		{"/test/bulk", `[{},{},{}]`},
+		{"/test/bulk?mode=sometimes", `[{}]`},
+	}
+
+	for _, r := range requests {
+		req, _ := http.NewRequest("PATCH", r.url, bytes.NewBufferString(r.body))
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, 400, resp.Code, r.url+" "+r.body)
+	}
+}
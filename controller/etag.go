@@ -0,0 +1,23 @@
+package controller
+
+import (
+	"api_boilerplate/versioning"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setETag sets the ETag header from the version of item.
+func (c *GenericController[T]) setETag(ctx *gin.Context, item T) {
+	if !c.Versioned {
+		return
+	}
+
+	fields, err := project(item, []string{versioning.Column})
+	if err != nil {
+		return
+	}
+
+	if version, ok := fields[versioning.Column].(float64); ok {
+		ctx.Header("ETag", versioning.ETag(int64(version)))
+	}
+}
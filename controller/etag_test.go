@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type versionedModel struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version int64  `json:"version"`
+}
+
+func TestGenericController_GetByIDETag(t *testing.T) {
+	svc := &MockService[versionedModel]{
+		GetByIDFn: func(id string, fields []string) (versionedModel, error) {
+			return versionedModel{ID: id, Name: "Test", Version: 3}, nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.Versioned = true
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("GET", "/test/01JW4MH8S671QVVGD0NYY1XWAP", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, `"3"`, resp.Header().Get("ETag"))
+}
+
+func TestGenericController_UpdateETag(t *testing.T) {
+	svc := &MockService[versionedModel]{
+		UpdateFn: func(id string, changes map[string]interface{}) (versionedModel, error) {
+			return versionedModel{ID: id, Name: "Updated", Version: 4}, nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.Versioned = true
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("PUT", "/test/01JW4MH8S671QVVGD0NYY1XWAP", bytes.NewBufferString(`{"name":"Updated"}`))
+	req.Header.Set("If-Match", `"3"`)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, `"4"`, resp.Header().Get("ETag"))
+	assert.JSONEq(t, `{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"Updated","version":4}`, resp.Body.String())
+}
+
+func TestGenericController_IfMatch(t *testing.T) {
+	svc := &MockService[versionedModel]{
+		DeleteFn: func(id string) error {
+			return apperror.PreconditionFailed(nil, "version mismatch")
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.Versioned = true
+	ctrl.RequireIfMatch = true
+	router := setupRouter(ctrl)
+
+	tests := []struct {
+		ifMatch string
+		status  int
+	}{
+		{"", 428},
+		{`2`, 400},
+		{`W/"2"`, 412},
+		{`"1", "2"`, 412},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("DELETE", "/test/01JW4MH8S671QVVGD0NYY1XWAP", nil)
+		if tt.ifMatch != "" {
+			req.Header.Set("If-Match", tt.ifMatch)
+		}
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, tt.status, resp.Code, tt.ifMatch)
+	}
+}
+
+func TestGenericController_IfMatchRequiredRoutes(t *testing.T) {
+	svc := &MockService[versionedModel]{
+		PatchManyFn: func(patches []service.BulkPatch, mode service.BulkMode) ([]service.BulkResult[versionedModel], error) {
+			t.Fatal("bulk patch bypassed If-Match")
+			return nil, nil
+		},
+		DeleteManyFn: func(ids []string, mode service.BulkMode) ([]service.BulkResult[versionedModel], error) {
+			t.Fatal("bulk delete bypassed If-Match")
+			return nil, nil
+		},
+		RestoreFn: func(id string) error {
+			t.Fatal("restore bypassed If-Match")
+			return nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.Versioned = true
+	ctrl.RequireIfMatch = true
+	ctrl.SoftDelete = true
+	router := setupRouter(ctrl)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		// Bulk paths fall through to the guarded /:id routes.
+		{"PATCH", "/test/bulk", `[{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"x"}]`, 428},
+		{"DELETE", "/test/bulk", `["01JW4MH8S671QVVGD0NYY1XWAP"]`, 428},
+		{"POST", "/test/01JW4MH8S671QVVGD0NYY1XWAP/restore", "", 404},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, tt.status, resp.Code, tt.method+" "+tt.path)
+	}
+}
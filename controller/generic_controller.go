@@ -1,90 +1,357 @@
 package controller
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
+	"strings"
+	"time"
 
+	"api_boilerplate/apperror"
+	"api_boilerplate/dialect"
 	"api_boilerplate/middleware"
+	"api_boilerplate/pagination"
+	"api_boilerplate/problem"
 	"api_boilerplate/service"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Route identifies one of the generic CRUD endpoints.
+type Route string
+
+const (
+	RouteList   Route = "list"
+	RouteGet    Route = "get"
+	RouteCreate Route = "create"
+	RouteUpdate Route = "update"
+	RoutePatch  Route = "patch"
+	RouteDelete Route = "delete"
+
+	RouteBulkCreate Route = "bulk_create"
+	RouteBulkPatch  Route = "bulk_patch"
+	RouteBulkDelete Route = "bulk_delete"
+
+	RouteRestore Route = "restore"
+	RoutePurge   Route = "purge"
+)
+
+var AllRoutes = []Route{
+	RouteList, RouteGet, RouteCreate, RouteUpdate, RoutePatch, RouteDelete,
+	RouteBulkCreate, RouteBulkPatch, RouteBulkDelete,
+	RouteRestore, RoutePurge,
+}
+
 type GenericController[T any] struct {
 	Service service.GenericService[T]
+	Fields  []string
+	Limits  pagination.Limits
+	// Dialect quotes the filter and sort columns; it must match the
+	// repository's.
+	Dialect dialect.Dialect
+	// Routes lists the endpoints to expose; empty exposes all of them.
+	Routes []Route
+	// Upsert makes PUT /:id create the entity when the id does not exist.
+	// It replaces the whole entity instead of keeping omitted fields.
+	Upsert bool
+	// UpsertKeys adds PUT /by-<key>/:value, which creates or replaces the
+	// entity by a natural key. Each key needs a unique index.
+	UpsertKeys []string
+	// MaxBulkItems caps the items of a bulk request; zero means
+	// DefaultMaxBulkItems.
+	MaxBulkItems int
+	// SoftDelete accepts with_deleted and only_deleted on reads and exposes
+	// POST /:id/restore. The repository must soft delete as well.
+	SoftDelete bool
+	// Retention is how long POST /purge keeps soft-deleted entities.
+	Retention time.Duration
+	// AdminToken guards POST /purge, which is not registered without it.
+	AdminToken string
+	// Versioned emits ETag headers and honors If-Match on PUT, PATCH and
+	// DELETE. The repository must be versioned as well.
+	Versioned bool
+	// RequireIfMatch rejects writes without If-Match with 428. The bulk
+	// writes and POST /:id/restore cannot carry one, so they are not
+	// registered.
+	RequireIfMatch bool
 }
 
-func NewGenericController[T any](s service.GenericService[T]) *GenericController[T] {
-	return &GenericController[T]{Service: s}
+func NewGenericController[T any](s service.GenericService[T], fields []string) *GenericController[T] {
+	return &GenericController[T]{Service: s, Fields: fields, Limits: pagination.DefaultLimits, Dialect: dialect.MySQL{}}
 }
 
-func (c *GenericController[T]) RegisterRoutes(r *gin.Engine, path string) {
+// RegisterRoutes mounts the enabled endpoints under path and returns the
+// group so callers can add their own routes to it.
+func (c *GenericController[T]) RegisterRoutes(r *gin.Engine, path string) *gin.RouterGroup {
 	group := r.Group(path)
-	group.GET("/", middleware.FilterMiddleware(), c.GetAll)
-	group.GET("/:id", c.GetByID)
-	group.POST("/", c.Create)
-	group.PUT("/:id", c.Update)
-	group.DELETE("/:id", c.Delete)
+
+	var scope, ifMatch []gin.HandlerFunc
+	if c.SoftDelete {
+		scope = append(scope, middleware.SoftDeleteMiddleware())
+	}
+	if c.Versioned {
+		ifMatch = append(ifMatch, middleware.IfMatchMiddleware(c.RequireIfMatch))
+	}
+
+	if c.exposes(RouteList) {
+		group.GET("/", append(
+			scope,
+			middleware.FilterMiddleware(c.Fields, c.Dialect),
+			middleware.SortMiddleware(c.Fields, c.Dialect.Quote),
+			middleware.FieldsMiddleware(c.Fields),
+			middleware.PaginationMiddleware(c.Limits),
+			c.GetAll,
+		)...)
+	}
+	if c.exposes(RouteGet) {
+		group.GET("/:id", append(scope, middleware.FieldsMiddleware(c.Fields), c.GetByID)...)
+	}
+	if c.exposes(RouteCreate) {
+		group.POST("/", c.Create)
+	}
+	if c.exposes(RouteUpdate) {
+		if c.Upsert {
+			group.PUT("/:id", append(ifMatch, c.upsert(path, "id", "id"))...)
+		} else {
+			group.PUT("/:id", append(ifMatch, c.Update)...)
+		}
+
+		for _, key := range c.UpsertKeys {
+			group.PUT(fmt.Sprintf("/by-%s/:value", key), append(ifMatch, c.upsert(path, key, "value"))...)
+		}
+	}
+	if c.exposes(RoutePatch) {
+		group.PATCH("/:id", append(ifMatch, c.Patch)...)
+	}
+	if c.exposes(RouteDelete) {
+		group.DELETE("/:id", append(ifMatch, c.Delete)...)
+	}
+	if c.exposes(RouteBulkCreate) {
+		group.POST("/bulk", c.CreateMany)
+	}
+	// Without a version to check, these would bypass If-Match.
+	unguarded := c.Versioned && c.RequireIfMatch
+
+	if c.exposes(RouteBulkPatch) && !unguarded {
+		group.PATCH("/bulk", c.PatchMany)
+	}
+	if c.exposes(RouteBulkDelete) && !unguarded {
+		group.DELETE("/bulk", c.DeleteMany)
+	}
+	if c.SoftDelete && c.exposes(RouteRestore) && !unguarded {
+		group.POST("/:id/restore", c.Restore)
+	}
+	if c.SoftDelete && c.AdminToken != "" && c.exposes(RoutePurge) {
+		group.POST("/purge", middleware.AdminMiddleware(c.AdminToken), c.Purge)
+	}
+
+	return group
+}
+
+func (c *GenericController[T]) exposes(route Route) bool {
+	return len(c.Routes) == 0 || slices.Contains(c.Routes, route)
 }
 
 func (c *GenericController[T]) GetAll(ctx *gin.Context) {
 	query, _ := ctx.Get("filtersSQL")
 	params, _ := ctx.Get("filtersQuery")
+	sort, _ := ctx.Get("sortSQL")
+	fields := ctx.GetStringSlice("fields")
+	page, _ := ctx.Get("pagination")
+
+	result, err := c.Service.GetAll(
+		ctx.Request.Context(),
+		query.(string),
+		params.(map[string]interface{}),
+		sort.(string),
+		fields,
+		page.(pagination.Params),
+	)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	if len(fields) == 0 {
+		ctx.JSON(http.StatusOK, result)
+		return
+	}
 
-	items, err := c.Service.GetAll(query.(string), params.(map[string]interface{}))
+	projected, err := projectPage(result, fields)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		problem.Respond(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, items)
+	ctx.JSON(http.StatusOK, projected)
 }
 
 func (c *GenericController[T]) GetByID(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	item, err := c.Service.GetByID(id)
+	fields := ctx.GetStringSlice("fields")
+
+	item, err := c.Service.GetByID(ctx.Request.Context(), id, fields)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		problem.Respond(ctx, err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, item)
+	c.setETag(ctx, item)
+
+	if len(fields) == 0 {
+		ctx.JSON(http.StatusOK, item)
+		return
+	}
+
+	projected, err := project(item, fields)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, projected)
 }
 
 func (c *GenericController[T]) Create(ctx *gin.Context) {
 	var item T
 
 	if err := ctx.ShouldBindJSON(&item); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		problem.Respond(ctx, apperror.Validation(err, "invalid request body"))
 		return
 	}
 
-	if err := c.Service.Create(item); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	created, err := c.Service.Create(ctx.Request.Context(), item)
+	if err != nil {
+		problem.Respond(ctx, err)
 		return
 	}
 
-	ctx.Status(http.StatusCreated)
+	if id, err := project(created, []string{"id"}); err == nil {
+		ctx.Header("Location", fmt.Sprintf("%s/%v", strings.TrimSuffix(ctx.Request.URL.Path, "/"), id["id"]))
+	}
+
+	c.setETag(ctx, created)
+	ctx.JSON(http.StatusCreated, created)
 }
 
 func (c *GenericController[T]) Update(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.Service.Update(id, ctx); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	// A map keeps apart the fields the client omitted, which keep their
+	// stored values.
+	var changes map[string]interface{}
+	if err := ctx.ShouldBindJSON(&changes); err != nil {
+		problem.Respond(ctx, apperror.Validation(err, "invalid request body"))
 		return
 	}
 
-	ctx.Status(http.StatusOK)
+	item, err := c.Service.Update(ctx.Request.Context(), id, changes)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	c.setETag(ctx, item)
+	ctx.JSON(http.StatusOK, item)
+}
+
+// upsert replaces or creates the entity whose key equals the param path
+// parameter, responding 201 with its Location when it was created.
+func (c *GenericController[T]) upsert(path string, key string, param string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		var item T
+		if err := ctx.ShouldBindJSON(&item); err != nil {
+			problem.Respond(ctx, apperror.Validation(err, "invalid request body"))
+			return
+		}
+
+		stored, created, err := c.Service.Upsert(ctx.Request.Context(), key, ctx.Param(param), item)
+		if err != nil {
+			problem.Respond(ctx, err)
+			return
+		}
+
+		c.setETag(ctx, stored)
+
+		if !created {
+			ctx.JSON(http.StatusOK, stored)
+			return
+		}
+
+		if id, err := project(stored, []string{"id"}); err == nil {
+			ctx.Header("Location", fmt.Sprintf("%s/%v", strings.TrimSuffix(path, "/"), id["id"]))
+		}
+
+		ctx.JSON(http.StatusCreated, stored)
+	}
+}
+
+func (c *GenericController[T]) Patch(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	patchType := service.PatchType(ctx.ContentType())
+	if patchType != service.MergePatch && patchType != service.JSONPatch {
+		problem.Write(ctx, problem.New(
+			http.StatusUnsupportedMediaType,
+			fmt.Sprintf("content type must be %s or %s", service.MergePatch, service.JSONPatch),
+		))
+		return
+	}
+
+	document, err := ctx.GetRawData()
+	if err != nil {
+		problem.Respond(ctx, apperror.Validation(err, "invalid request body"))
+		return
+	}
+
+	item, err := c.Service.Patch(ctx.Request.Context(), id, patchType, document)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	c.setETag(ctx, item)
+	ctx.JSON(http.StatusOK, item)
 }
 
 func (c *GenericController[T]) Delete(ctx *gin.Context) {
 	id := ctx.Param("id")
 
-	if err := c.Service.Delete(id); err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	if err := c.Service.Delete(ctx.Request.Context(), id); err != nil {
+		problem.Respond(ctx, err)
 		return
 	}
 
 	ctx.Status(http.StatusNoContent)
 }
+
+// Restore undoes a soft delete and responds with the restored entity.
+func (c *GenericController[T]) Restore(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := c.Service.Restore(ctx.Request.Context(), id); err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	item, err := c.Service.GetByID(ctx.Request.Context(), id, nil)
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	c.setETag(ctx, item)
+	ctx.JSON(http.StatusOK, item)
+}
+
+// Purge permanently removes entities soft-deleted longer than Retention ago.
+func (c *GenericController[T]) Purge(ctx *gin.Context) {
+	purged, err := c.Service.Purge(ctx.Request.Context(), time.Now().Add(-c.Retention))
+	if err != nil {
+		problem.Respond(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"purged": purged})
+}
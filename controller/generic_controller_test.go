@@ -2,10 +2,17 @@ package controller
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/pagination"
+	"api_boilerplate/service"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
@@ -16,21 +23,61 @@ type TestModel struct {
 	Name string `json:"name"`
 }
 
+var testFields = []string{"id", "name"}
+
 type MockService[T any] struct {
-	GetAllFn  func() ([]T, error)
-	GetByIDFn func(string) (T, error)
-	CreateFn  func(T) error
-	UpdateFn  func(string, *gin.Context) error
+	GetAllFn  func(string, pagination.Params) (pagination.Page[T], error)
+	GetByIDFn func(string, []string) (T, error)
+	CreateFn  func(T) (T, error)
+	UpdateFn  func(string, map[string]interface{}) (T, error)
+	PatchFn   func(string, service.PatchType, []byte) (T, error)
 	DeleteFn  func(string) error
+
+	UpsertFn     func(string, string, T) (T, bool, error)
+	CreateManyFn func([]T, service.BulkMode) ([]service.BulkResult[T], error)
+	PatchManyFn  func([]service.BulkPatch, service.BulkMode) ([]service.BulkResult[T], error)
+	DeleteManyFn func([]string, service.BulkMode) ([]service.BulkResult[T], error)
+	RestoreFn    func(string) error
+	PurgeFn      func(time.Time) (int64, error)
 }
 
-func (m *MockService[T]) GetAll(query string, filters map[string]interface{}) ([]T, error) {
-	return m.GetAllFn()
+func (m *MockService[T]) GetAll(
+	ctx context.Context,
+	query string,
+	filters map[string]interface{},
+	sort string,
+	fields []string,
+	page pagination.Params,
+) (pagination.Page[T], error) {
+	return m.GetAllFn(sort, page)
+}
+func (m *MockService[T]) GetByID(ctx context.Context, id string, fields []string) (T, error) {
+	return m.GetByIDFn(id, fields)
+}
+func (m *MockService[T]) Create(ctx context.Context, item T) (T, error) { return m.CreateFn(item) }
+func (m *MockService[T]) Update(ctx context.Context, id string, changes map[string]interface{}) (T, error) {
+	return m.UpdateFn(id, changes)
+}
+func (m *MockService[T]) Delete(ctx context.Context, id string) error { return m.DeleteFn(id) }
+func (m *MockService[T]) Patch(ctx context.Context, id string, patchType service.PatchType, document []byte) (T, error) {
+	return m.PatchFn(id, patchType, document)
+}
+func (m *MockService[T]) Upsert(ctx context.Context, key string, value string, item T) (T, bool, error) {
+	return m.UpsertFn(key, value, item)
+}
+func (m *MockService[T]) CreateMany(ctx context.Context, items []T, mode service.BulkMode) ([]service.BulkResult[T], error) {
+	return m.CreateManyFn(items, mode)
+}
+func (m *MockService[T]) PatchMany(ctx context.Context, patches []service.BulkPatch, mode service.BulkMode) ([]service.BulkResult[T], error) {
+	return m.PatchManyFn(patches, mode)
+}
+func (m *MockService[T]) DeleteMany(ctx context.Context, ids []string, mode service.BulkMode) ([]service.BulkResult[T], error) {
+	return m.DeleteManyFn(ids, mode)
+}
+func (m *MockService[T]) Restore(ctx context.Context, id string) error { return m.RestoreFn(id) }
+func (m *MockService[T]) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return m.PurgeFn(before)
 }
-func (m *MockService[T]) GetByID(id string) (T, error)             { return m.GetByIDFn(id) }
-func (m *MockService[T]) Create(item T) error                      { return m.CreateFn(item) }
-func (m *MockService[T]) Update(id string, ctx *gin.Context) error { return m.UpdateFn(id, ctx) }
-func (m *MockService[T]) Delete(id string) error                   { return m.DeleteFn(id) }
 
 func setupRouter[T any](controller *GenericController[T]) *gin.Engine {
 	gin.SetMode(gin.TestMode)
@@ -41,11 +88,16 @@ func setupRouter[T any](controller *GenericController[T]) *gin.Engine {
 
 func TestGenericController_GetAll(t *testing.T) {
 	service := &MockService[TestModel]{
-		GetAllFn: func() ([]TestModel, error) {
-			return []TestModel{{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Test"}}, nil
+		GetAllFn: func(sort string, page pagination.Params) (pagination.Page[TestModel], error) {
+			assert.Equal(t, pagination.DefaultLimit, page.Limit)
+			return pagination.Page[TestModel]{
+				Items: []TestModel{{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Test"}},
+				Total: 1,
+				Limit: page.Limit,
+			}, nil
 		},
 	}
-	ctrl := NewGenericController(service)
+	ctrl := NewGenericController(service, testFields)
 	router := setupRouter(ctrl)
 
 	req, _ := http.NewRequest("GET", "/test/", nil)
@@ -53,21 +105,120 @@ func TestGenericController_GetAll(t *testing.T) {
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, 200, resp.Code)
-	var body []TestModel
+	var body pagination.Page[TestModel]
+	err := json.Unmarshal(resp.Body.Bytes(), &body)
+	assert.NoError(t, err)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", body.Items[0].ID)
+	assert.Equal(t, 1, body.Total)
+	assert.False(t, body.HasMore)
+}
+
+func TestGenericController_GetAllPagination(t *testing.T) {
+	service := &MockService[TestModel]{
+		GetAllFn: func(sort string, page pagination.Params) (pagination.Page[TestModel], error) {
+			assert.Equal(t, 5, page.Limit)
+			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", page.Cursor)
+			return pagination.Page[TestModel]{Items: []TestModel{}, Limit: page.Limit}, nil
+		},
+	}
+	ctrl := NewGenericController(service, testFields)
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("GET", "/test/?limit=5&cursor=01JW4MH8S671QVVGD0NYY1XWAP", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/test/?limit=500", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestGenericController_GetAllSort(t *testing.T) {
+	service := &MockService[TestModel]{
+		GetAllFn: func(sort string, page pagination.Params) (pagination.Page[TestModel], error) {
+			assert.Equal(t, "ORDER BY `name` DESC, `id` ASC", sort)
+			return pagination.Page[TestModel]{Items: []TestModel{}, Limit: page.Limit}, nil
+		},
+	}
+	ctrl := NewGenericController(service, testFields)
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("GET", "/test/?sort=-name,id", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+
+	req, _ = http.NewRequest("GET", "/test/?sort=price", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestGenericController_GetAllFields(t *testing.T) {
+	service := &MockService[TestModel]{
+		GetAllFn: func(sort string, page pagination.Params) (pagination.Page[TestModel], error) {
+			return pagination.Page[TestModel]{
+				Items: []TestModel{{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Test"}},
+				Total: 1,
+				Limit: page.Limit,
+			}, nil
+		},
+	}
+	ctrl := NewGenericController(service, testFields)
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("GET", "/test/?fields=name", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	var body pagination.Page[map[string]interface{}]
 	err := json.Unmarshal(resp.Body.Bytes(), &body)
 	assert.NoError(t, err)
-	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", body[0].ID)
+	assert.Equal(t, map[string]interface{}{"name": "Test"}, body.Items[0])
+	assert.Equal(t, 1, body.Total)
+}
+
+func TestGenericController_GetByIDFields(t *testing.T) {
+	service := &MockService[TestModel]{
+		GetByIDFn: func(id string, fields []string) (TestModel, error) {
+			assert.Equal(t, []string{"name"}, fields)
+			return TestModel{ID: id, Name: "Test"}, nil
+		},
+	}
+	ctrl := NewGenericController(service, testFields)
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("GET", "/test/01JW4MH8S671QVVGD0NYY1XWAP?fields=name", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"name":"Test"}`, resp.Body.String())
+
+	req, _ = http.NewRequest("GET", "/test/01JW4MH8S671QVVGD0NYY1XWAP?fields=password", nil)
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
 }
 
 func TestGenericController_Create(t *testing.T) {
 	called := false
 	service := &MockService[TestModel]{
-		CreateFn: func(item TestModel) error {
+		CreateFn: func(item TestModel) (TestModel, error) {
 			called = true
-			return nil
+			item.ID = "01JW4MH8S671QVVGD0NYY1XWAP"
+			return item, nil
 		},
 	}
-	ctrl := NewGenericController(service)
+	ctrl := NewGenericController(service, testFields)
 	router := setupRouter(ctrl)
 
 	body, _ := json.Marshal(TestModel{Name: "New"})
@@ -78,6 +229,8 @@ func TestGenericController_Create(t *testing.T) {
 
 	assert.Equal(t, 201, resp.Code)
 	assert.True(t, called)
+	assert.Equal(t, "/test/01JW4MH8S671QVVGD0NYY1XWAP", resp.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"New"}`, resp.Body.String())
 }
 
 func TestGenericController_Delete(t *testing.T) {
@@ -88,7 +241,7 @@ func TestGenericController_Delete(t *testing.T) {
 			return nil
 		},
 	}
-	ctrl := NewGenericController(service)
+	ctrl := NewGenericController(service, testFields)
 	router := setupRouter(ctrl)
 
 	req, _ := http.NewRequest("DELETE", "/test/01JW4MH8S671QVVGD0NYY1XWAP", nil)
@@ -102,12 +255,14 @@ func TestGenericController_Delete(t *testing.T) {
 func TestGenericController_Update(t *testing.T) {
 	called := false
 	service := &MockService[TestModel]{
-		UpdateFn: func(id string, ctx *gin.Context) error {
+		UpdateFn: func(id string, changes map[string]interface{}) (TestModel, error) {
+			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", id)
+			assert.Equal(t, map[string]interface{}{"id": "", "name": "Updated"}, changes)
 			called = true
-			return nil
+			return TestModel{ID: id, Name: "Updated"}, nil
 		},
 	}
-	ctrl := NewGenericController(service)
+	ctrl := NewGenericController(service, testFields)
 	router := setupRouter(ctrl)
 
 	body, _ := json.Marshal(TestModel{Name: "Updated"})
@@ -119,3 +274,139 @@ func TestGenericController_Update(t *testing.T) {
 	assert.Equal(t, 200, resp.Code)
 	assert.True(t, called)
 }
+
+func TestGenericController_Upsert(t *testing.T) {
+	svc := &MockService[TestModel]{
+		UpsertFn: func(key string, value string, item TestModel) (TestModel, bool, error) {
+			if key == "name" {
+				return TestModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: value}, false, nil
+			}
+			return TestModel{ID: value, Name: item.Name}, true, nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.Upsert = true
+	ctrl.UpsertKeys = []string{"name"}
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("PUT", "/test/01JW4MH8S671QVVGD0NYY1XWAP", bytes.NewBufferString(`{"name":"New"}`))
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 201, resp.Code)
+	assert.Equal(t, "/test/01JW4MH8S671QVVGD0NYY1XWAP", resp.Header().Get("Location"))
+	assert.JSONEq(t, `{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"New"}`, resp.Body.String())
+
+	req, _ = http.NewRequest("PUT", "/test/by-name/john", bytes.NewBufferString(`{}`))
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"john"}`, resp.Body.String())
+}
+
+func TestGenericController_Patch(t *testing.T) {
+	svc := &MockService[TestModel]{
+		PatchFn: func(id string, patchType service.PatchType, document []byte) (TestModel, error) {
+			assert.Equal(t, service.MergePatch, patchType)
+			assert.JSONEq(t, `{"name":"Patched"}`, string(document))
+			return TestModel{ID: id, Name: "Patched"}, nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("PATCH", "/test/01JW4MH8S671QVVGD0NYY1XWAP", bytes.NewBufferString(`{"name":"Patched"}`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"Patched"}`, resp.Body.String())
+}
+
+func TestGenericController_PatchErrors(t *testing.T) {
+	svc := &MockService[TestModel]{
+		PatchFn: func(id string, patchType service.PatchType, document []byte) (TestModel, error) {
+			return TestModel{}, apperror.Validation(service.ErrInvalidPatch, "invalid patch document")
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("PATCH", "/test/01JW4MH8S671QVVGD0NYY1XWAP", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 415, resp.Code)
+
+	req, _ = http.NewRequest("PATCH", "/test/01JW4MH8S671QVVGD0NYY1XWAP", bytes.NewBufferString(`[]`))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestGenericController_ErrorStatus(t *testing.T) {
+	svc := &MockService[TestModel]{
+		GetByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{}, apperror.NotFound(nil, "test %s not found", id)
+		},
+		DeleteFn: func(id string) error {
+			return apperror.Forbidden(nil, "cannot delete test")
+		},
+		GetAllFn: func(sort string, page pagination.Params) (pagination.Page[TestModel], error) {
+			return pagination.Page[TestModel]{}, errors.New("connection refused")
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	router := setupRouter(ctrl)
+
+	cases := []struct {
+		method string
+		path   string
+		status int
+	}{
+		{"GET", "/test/01JW4MH8S671QVVGD0NYY1XWAP", 404},
+		{"PUT", "/test/01JW4MH8S671QVVGD0NYY1XWAP", 400},
+		{"DELETE", "/test/01JW4MH8S671QVVGD0NYY1XWAP", 403},
+		{"GET", "/test/", 500},
+		{"POST", "/test/", 400},
+	}
+
+	for _, c := range cases {
+		req, _ := http.NewRequest(c.method, c.path, bytes.NewBufferString(`{`))
+		req.Header.Set("Content-Type", "application/json")
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, c.status, resp.Code, c.method+" "+c.path)
+		assert.Equal(t, "application/problem+json", resp.Header().Get("Content-Type"))
+	}
+}
+
+func TestGenericController_ErrorHidesInternalDetails(t *testing.T) {
+	svc := &MockService[TestModel]{
+		GetByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{}, errors.New("Error 1146: Table 'api_boilerplate.test' doesn't exist")
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("GET", "/test/01JW4MH8S671QVVGD0NYY1XWAP", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 500, resp.Code)
+	assert.NotContains(t, resp.Body.String(), "api_boilerplate.test")
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Internal Server Error",
+		"status": 500,
+		"detail": "an unexpected error occurred",
+		"instance": "/test/01JW4MH8S671QVVGD0NYY1XWAP"
+	}`, resp.Body.String())
+}
@@ -0,0 +1,51 @@
+package controller
+
+import (
+	"encoding/json"
+	"slices"
+
+	"api_boilerplate/pagination"
+)
+
+// project keeps only the requested keys of item's JSON representation.
+func project[T any](item T, fields []string) (map[string]interface{}, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, err
+	}
+
+	for key := range result {
+		if !slices.Contains(fields, key) {
+			delete(result, key)
+		}
+	}
+
+	return result, nil
+}
+
+func projectPage[T any](page pagination.Page[T], fields []string) (pagination.Page[map[string]interface{}], error) {
+	result := pagination.Page[map[string]interface{}]{
+		Items:      make([]map[string]interface{}, 0, len(page.Items)),
+		Total:      page.Total,
+		Limit:      page.Limit,
+		Offset:     page.Offset,
+		NextCursor: page.NextCursor,
+		HasMore:    page.HasMore,
+	}
+
+	for _, item := range page.Items {
+		projected, err := project(item, fields)
+		if err != nil {
+			return result, err
+		}
+
+		result.Items = append(result.Items, projected)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,107 @@
+package controller
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"api_boilerplate/pagination"
+	"api_boilerplate/softdelete"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenericController_SoftDeleteScope(t *testing.T) {
+	var scope softdelete.Scope
+	svc := &MockService[TestModel]{
+		GetAllFn: func(sort string, page pagination.Params) (pagination.Page[TestModel], error) {
+			return pagination.Page[TestModel]{Items: []TestModel{}}, nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.SoftDelete = true
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(ctx *gin.Context) {
+		ctx.Next()
+		scope = softdelete.FromContext(ctx.Request.Context())
+	})
+	ctrl.RegisterRoutes(r, "/test")
+
+	req, _ := http.NewRequest("GET", "/test/?only_deleted=true", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.Equal(t, softdelete.OnlyDeleted, scope)
+
+	req, _ = http.NewRequest("GET", "/test/?with_deleted=true&only_deleted=true", nil)
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+}
+
+func TestGenericController_Restore(t *testing.T) {
+	svc := &MockService[TestModel]{
+		RestoreFn: func(id string) error {
+			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", id)
+			return nil
+		},
+		GetByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "Restored"}, nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.SoftDelete = true
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("POST", "/test/01JW4MH8S671QVVGD0NYY1XWAP/restore", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"Restored"}`, resp.Body.String())
+}
+
+func TestGenericController_Purge(t *testing.T) {
+	svc := &MockService[TestModel]{
+		PurgeFn: func(before time.Time) (int64, error) {
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Minute)
+			return 3, nil
+		},
+	}
+	ctrl := NewGenericController(svc, testFields)
+	ctrl.SoftDelete = true
+	ctrl.Retention = 24 * time.Hour
+	ctrl.AdminToken = "secret"
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("POST", "/test/purge", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 401, resp.Code)
+
+	req.Header.Set("Authorization", "Bearer secret")
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(t, `{"purged":3}`, resp.Body.String())
+}
+
+func TestGenericController_PurgeRequiresAdminToken(t *testing.T) {
+	ctrl := NewGenericController(&MockService[TestModel]{}, testFields)
+	ctrl.SoftDelete = true
+	router := setupRouter(ctrl)
+
+	req, _ := http.NewRequest("POST", "/test/purge", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 404, resp.Code)
+}
@@ -4,21 +4,52 @@ import (
 	"log"
 	"time"
 
-	_ "github.com/go-sql-driver/mysql"
+	"api_boilerplate/config"
+	"api_boilerplate/dialect"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/jmoiron/sqlx"
+	_ "modernc.org/sqlite"
 )
 
-func GetDBConnection() *sqlx.DB {
-	db, err := sqlx.Connect("mysql", "root:root@/api_boilerplate")
+func GetDBConnection(cfg config.DatabaseConfig) *sqlx.DB {
+	driver, err := dialect.Driver(cfg.Dialect)
+	if err != nil {
+		log.Fatalln("Error opening database: ", err)
+	}
+
+	dsn, err := connectionDSN(driver, cfg.DSN)
+	if err != nil {
+		log.Fatalln("Error opening database: ", err)
+	}
+
+	db, err := sqlx.Connect(driver, dsn)
 
 	if err != nil {
 		log.Fatalln("Error opening database: ", err)
 	}
 
-	// See "Important settings" section.
-	db.SetConnMaxLifetime(time.Minute * 3)
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(10)
+	db.SetConnMaxLifetime(time.Duration(cfg.ConnMaxLifetime))
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
 
 	return db
 }
+
+// connectionDSN adjusts the configured DSN to what the repositories expect.
+// On MySQL, clientFoundRows makes RowsAffected count matched rows, so an
+// UPDATE that changes nothing is not mistaken for a missing id.
+func connectionDSN(driver string, dsn string) (string, error) {
+	if driver != "mysql" {
+		return dsn, nil
+	}
+
+	parsed, err := mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", err
+	}
+
+	parsed.ClientFoundRows = true
+	return parsed.FormatDSN(), nil
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectionDSN(t *testing.T) {
+	dsn, err := connectionDSN("mysql", "root:root@tcp(db:3306)/app?parseTime=true")
+	require.NoError(t, err)
+
+	parsed, err := mysql.ParseDSN(dsn)
+	require.NoError(t, err)
+	assert.True(t, parsed.ClientFoundRows)
+	assert.True(t, parsed.ParseTime)
+	assert.Equal(t, "db:3306", parsed.Addr)
+
+	dsn, err = connectionDSN("pgx", "postgres://localhost/app")
+	require.NoError(t, err)
+	assert.Equal(t, "postgres://localhost/app", dsn)
+
+	_, err = connectionDSN("mysql", "not a dsn")
+	assert.Error(t, err)
+}
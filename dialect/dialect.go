@@ -0,0 +1,123 @@
+package dialect
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Dialect isolates the SQL differences between the supported databases.
+// Statements are written with named (:name) or "?" parameters and rebound
+// by sqlx according to BindType.
+type Dialect interface {
+	Name() string
+	BindType() int
+	Quote(identifier string) string
+	// Timestamp converts t to the value bound for DATETIME/TIMESTAMP columns.
+	Timestamp(t time.Time) interface{}
+	LimitOffset(limit int, offset int) string
+	// Like renders a LIKE condition on column whose pattern escapes
+	// wildcards with a backslash.
+	Like(column string, pattern string, negate bool) string
+	// MaxParams is the number of bind parameters a single statement accepts.
+	MaxParams() int
+	// Upsert renders an INSERT of columns that updates the update columns,
+	// and adds one to the increment columns, when a row with the same
+	// conflict columns already exists.
+	Upsert(table string, columns []string, conflict []string, update []string, increment []string) string
+	IsDuplicateKey(err error) bool
+}
+
+// ForDriver returns the dialect for a database/sql driver name. Unknown
+// drivers fall back to MySQL, the project's default database.
+func ForDriver(driver string) Dialect {
+	switch driver {
+	case "pgx", "postgres":
+		return Postgres{}
+	case "sqlite3", "sqlite":
+		return SQLite{}
+	default:
+		return MySQL{}
+	}
+}
+
+// Driver maps a configured dialect name to the database/sql driver name.
+func Driver(name string) (string, error) {
+	switch name {
+	case "", "mysql":
+		return "mysql", nil
+	case "postgres":
+		return "pgx", nil
+	case "sqlite":
+		return "sqlite", nil
+	default:
+		return "", fmt.Errorf("unsupported database dialect %q", name)
+	}
+}
+
+// Rebind converts "?" placeholders to the dialect's bind style.
+func Rebind(d Dialect, query string) string {
+	return sqlx.Rebind(d.BindType(), query)
+}
+
+func QuoteAll(d Dialect, identifiers []string) []string {
+	quoted := make([]string, len(identifiers))
+	for i, identifier := range identifiers {
+		quoted[i] = d.Quote(identifier)
+	}
+
+	return quoted
+}
+
+func quote(identifier string, mark string) string {
+	return mark + strings.ReplaceAll(identifier, mark, mark+mark) + mark
+}
+
+func limitOffset(limit int, offset int) string {
+	return fmt.Sprintf("LIMIT %d OFFSET %d", limit, offset)
+}
+
+// like renders a LIKE condition whose ESCAPE clause is the literal escape.
+func like(column string, pattern string, negate bool, escape string) string {
+	keyword := "LIKE"
+	if negate {
+		keyword = "NOT LIKE"
+	}
+
+	return fmt.Sprintf("%s %s %s ESCAPE %s", column, keyword, pattern, escape)
+}
+
+func insert(d Dialect, table string, columns []string) string {
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (:%s)",
+		d.Quote(table),
+		strings.Join(QuoteAll(d, columns), ", "),
+		strings.Join(columns, ", :"),
+	)
+}
+
+// onConflict renders the ON CONFLICT upsert shared by Postgres and SQLite.
+func onConflict(d Dialect, table string, columns []string, conflict []string, update []string, increment []string) string {
+	var set []string
+	for _, column := range update {
+		set = append(set, fmt.Sprintf("%s = EXCLUDED.%s", d.Quote(column), d.Quote(column)))
+	}
+	// Unqualified references to the existing row are ambiguous with EXCLUDED.
+	for _, column := range increment {
+		set = append(set, fmt.Sprintf("%s = %s.%s + 1", d.Quote(column), d.Quote(table), d.Quote(column)))
+	}
+
+	action := "DO NOTHING"
+	if len(set) > 0 {
+		action = "DO UPDATE SET " + strings.Join(set, ", ")
+	}
+
+	return fmt.Sprintf(
+		"%s ON CONFLICT (%s) %s",
+		insert(d, table, columns),
+		strings.Join(QuoteAll(d, conflict), ", "),
+		action,
+	)
+}
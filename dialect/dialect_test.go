@@ -0,0 +1,81 @@
+package dialect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForDriver(t *testing.T) {
+	assert.Equal(t, MySQL{}, ForDriver("mysql"))
+	assert.Equal(t, Postgres{}, ForDriver("pgx"))
+	assert.Equal(t, SQLite{}, ForDriver("sqlite"))
+	assert.Equal(t, MySQL{}, ForDriver("sqlmock"))
+}
+
+func TestDriver(t *testing.T) {
+	driver, err := Driver("postgres")
+	assert.NoError(t, err)
+	assert.Equal(t, "pgx", driver)
+
+	driver, err = Driver("")
+	assert.NoError(t, err)
+	assert.Equal(t, "mysql", driver)
+
+	_, err = Driver("oracle")
+	assert.Error(t, err)
+}
+
+func TestQuote(t *testing.T) {
+	assert.Equal(t, "`name`", MySQL{}.Quote("name"))
+	assert.Equal(t, "`a``b`", MySQL{}.Quote("a`b"))
+	assert.Equal(t, `"name"`, Postgres{}.Quote("name"))
+	assert.Equal(t, `"a""b"`, SQLite{}.Quote(`a"b`))
+}
+
+func TestRebind(t *testing.T) {
+	query := "SELECT * FROM t WHERE a = ? AND b = ?"
+
+	assert.Equal(t, query, Rebind(MySQL{}, query))
+	assert.Equal(t, "SELECT * FROM t WHERE a = $1 AND b = $2", Rebind(Postgres{}, query))
+}
+
+func TestLike(t *testing.T) {
+	assert.Equal(t, "`name` LIKE :name_1 ESCAPE '\\\\'", MySQL{}.Like("`name`", ":name_1", false))
+	assert.Equal(t, `"name" NOT LIKE $1 ESCAPE '\'`, Postgres{}.Like(`"name"`, "$1", true))
+	assert.Equal(t, `"name" LIKE ? ESCAPE '\'`, SQLite{}.Like(`"name"`, "?", false))
+}
+
+func TestUpsert(t *testing.T) {
+	columns := []string{"id", "email", "name"}
+
+	assert.Equal(t,
+		"INSERT INTO `user` (`id`, `email`, `name`) VALUES (:id, :email, :name) AS `new` ON DUPLICATE KEY UPDATE `name` = `new`.`name`",
+		MySQL{}.Upsert("user", columns, []string{"email"}, []string{"name"}, nil),
+	)
+	assert.Equal(t,
+		`INSERT INTO "user" ("id", "email", "name") VALUES (:id, :email, :name) ON CONFLICT ("email") DO UPDATE SET "name" = EXCLUDED."name"`,
+		Postgres{}.Upsert("user", columns, []string{"email"}, []string{"name"}, nil),
+	)
+}
+
+func TestUpsertIncrement(t *testing.T) {
+	columns := []string{"id", "version"}
+
+	assert.Equal(t,
+		"INSERT INTO `user` (`id`, `version`) VALUES (:id, :version) AS `new` ON DUPLICATE KEY UPDATE `version` = `user`.`version` + 1",
+		MySQL{}.Upsert("user", columns, []string{"id"}, nil, []string{"version"}),
+	)
+	assert.Equal(t,
+		`INSERT INTO "user" ("id", "version") VALUES (:id, :version) ON CONFLICT ("id") DO UPDATE SET "version" = "user"."version" + 1`,
+		Postgres{}.Upsert("user", columns, []string{"id"}, nil, []string{"version"}),
+	)
+	assert.Equal(t,
+		"INSERT INTO `user` (`id`) VALUES (:id) AS `new` ON DUPLICATE KEY UPDATE `id` = `id`",
+		MySQL{}.Upsert("user", []string{"id"}, []string{"id"}, nil, nil),
+	)
+	assert.Equal(t,
+		`INSERT INTO "user" ("id") VALUES (:id) ON CONFLICT ("id") DO NOTHING`,
+		SQLite{}.Upsert("user", []string{"id"}, []string{"id"}, nil, nil),
+	)
+}
@@ -0,0 +1,79 @@
+package dialect
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jmoiron/sqlx"
+)
+
+// mysqlDuplicateEntry is ER_DUP_ENTRY, raised on primary or unique key violations.
+const mysqlDuplicateEntry = 1062
+
+type MySQL struct{}
+
+func (MySQL) Name() string {
+	return "mysql"
+}
+
+func (MySQL) BindType() int {
+	return sqlx.QUESTION
+}
+
+func (MySQL) Quote(identifier string) string {
+	return quote(identifier, "`")
+}
+
+// Timestamp formats t for DATETIME columns, which MySQL reads without a time zone.
+func (MySQL) Timestamp(t time.Time) interface{} {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func (MySQL) LimitOffset(limit int, offset int) string {
+	return limitOffset(limit, offset)
+}
+
+// Like writes the backslash escaped, as MySQL string literals treat it as an
+// escape character unless NO_BACKSLASH_ESCAPES is set.
+func (MySQL) Like(column string, pattern string, negate bool) string {
+	return like(column, pattern, negate, `'\\'`)
+}
+
+func (MySQL) MaxParams() int {
+	return 65535
+}
+
+// mysqlRowAlias names the inserted row in ON DUPLICATE KEY UPDATE.
+const mysqlRowAlias = "new"
+
+// Upsert refers to the inserted row through a row alias, which replaces the
+// deprecated VALUES() function and needs MySQL 8.0.19 or later.
+func (d MySQL) Upsert(table string, columns []string, conflict []string, update []string, increment []string) string {
+	var set []string
+	for _, column := range update {
+		set = append(set, fmt.Sprintf("%s = %s.%s", d.Quote(column), d.Quote(mysqlRowAlias), d.Quote(column)))
+	}
+	for _, column := range increment {
+		set = append(set, fmt.Sprintf("%s = %s.%s + 1", d.Quote(column), d.Quote(table), d.Quote(column)))
+	}
+	if len(set) == 0 {
+		// A no-op assignment keeps the existing row.
+		set = append(set, fmt.Sprintf("%s = %s", d.Quote(conflict[0]), d.Quote(conflict[0])))
+	}
+
+	// MySQL resolves conflicts on any unique key, so conflict is implied.
+	return fmt.Sprintf(
+		"%s AS %s ON DUPLICATE KEY UPDATE %s",
+		insert(d, table, columns),
+		d.Quote(mysqlRowAlias),
+		strings.Join(set, ", "),
+	)
+}
+
+func (MySQL) IsDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlDuplicateEntry
+}
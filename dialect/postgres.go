@@ -0,0 +1,52 @@
+package dialect
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jmoiron/sqlx"
+)
+
+// postgresUniqueViolation is the unique_violation SQLSTATE.
+const postgresUniqueViolation = "23505"
+
+type Postgres struct{}
+
+func (Postgres) Name() string {
+	return "postgres"
+}
+
+func (Postgres) BindType() int {
+	return sqlx.DOLLAR
+}
+
+func (Postgres) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+// Timestamp is bound as time.Time so pgx encodes it for TIMESTAMP columns.
+func (Postgres) Timestamp(t time.Time) interface{} {
+	return t
+}
+
+func (Postgres) LimitOffset(limit int, offset int) string {
+	return limitOffset(limit, offset)
+}
+
+func (Postgres) Like(column string, pattern string, negate bool) string {
+	return like(column, pattern, negate, `'\'`)
+}
+
+func (Postgres) MaxParams() int {
+	return 65535
+}
+
+func (d Postgres) Upsert(table string, columns []string, conflict []string, update []string, increment []string) string {
+	return onConflict(d, table, columns, conflict, update, increment)
+}
+
+func (Postgres) IsDuplicateKey(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == postgresUniqueViolation
+}
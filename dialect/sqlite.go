@@ -0,0 +1,53 @@
+package dialect
+
+import (
+	"errors"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+type SQLite struct{}
+
+func (SQLite) Name() string {
+	return "sqlite"
+}
+
+func (SQLite) BindType() int {
+	return sqlx.QUESTION
+}
+
+func (SQLite) Quote(identifier string) string {
+	return quote(identifier, `"`)
+}
+
+// Timestamp uses the text format understood by SQLite's date functions.
+func (SQLite) Timestamp(t time.Time) interface{} {
+	return t.Format("2006-01-02 15:04:05")
+}
+
+func (SQLite) LimitOffset(limit int, offset int) string {
+	return limitOffset(limit, offset)
+}
+
+// MaxParams is SQLITE_MAX_VARIABLE_NUMBER since SQLite 3.32.
+func (SQLite) Like(column string, pattern string, negate bool) string {
+	return like(column, pattern, negate, `'\'`)
+}
+
+func (SQLite) MaxParams() int {
+	return 32766
+}
+
+func (d SQLite) Upsert(table string, columns []string, conflict []string, update []string, increment []string) string {
+	return onConflict(d, table, columns, conflict, update, increment)
+}
+
+func (SQLite) IsDuplicateKey(err error) bool {
+	var sqliteErr *sqlite.Error
+	return errors.As(err, &sqliteErr) &&
+		(sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE ||
+			sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY)
+}
@@ -0,0 +1,26 @@
+package health
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Database pings db and reports its connection pool statistics.
+func Database(db *sqlx.DB) CheckFunc {
+	return func(ctx context.Context) (map[string]interface{}, error) {
+		err := db.PingContext(ctx)
+
+		stats := db.Stats()
+		details := map[string]interface{}{
+			"open_connections":     stats.OpenConnections,
+			"in_use":               stats.InUse,
+			"idle":                 stats.Idle,
+			"max_open_connections": stats.MaxOpenConnections,
+			"wait_count":           stats.WaitCount,
+			"wait_duration":        stats.WaitDuration.String(),
+		}
+
+		return details, err
+	}
+}
@@ -0,0 +1,151 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+
+	DefaultTimeout = 2 * time.Second
+)
+
+// CheckFunc probes a dependency. The returned details are reported as is,
+// even when the check fails.
+type CheckFunc func(ctx context.Context) (map[string]interface{}, error)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Status   string                 `json:"status"`
+	Required bool                   `json:"required"`
+	Duration string                 `json:"duration"`
+	Error    string                 `json:"error,omitempty"`
+	Details  map[string]interface{} `json:"details,omitempty"`
+}
+
+// Report is the body of the readiness endpoint.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks"`
+}
+
+type check struct {
+	required bool
+	fn       CheckFunc
+}
+
+// Registry holds the dependency checks run by the readiness endpoint.
+type Registry struct {
+	Timeout time.Duration
+
+	mu     sync.RWMutex
+	checks map[string]check
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	return &Registry{Timeout: timeout, checks: map[string]check{}}
+}
+
+// Register adds or replaces the check called name. Failing optional checks
+// are reported but do not make the service unready.
+func (r *Registry) Register(name string, required bool, fn CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks[name] = check{required: required, fn: fn}
+}
+
+// Check runs every check concurrently, each bounded by Timeout.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make(map[string]check, len(r.checks))
+	for name, c := range r.checks {
+		checks[name] = c
+	}
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusUp, Checks: make(map[string]Result, len(checks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			result := r.run(ctx, name, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if result.Status == StatusDown && c.required {
+				report.Status = StatusDown
+			}
+		}()
+	}
+	wg.Wait()
+
+	return report
+}
+
+// run executes c. Errors are logged rather than reported, since the
+// readiness endpoint is public and driver errors may reveal hosts or DSNs.
+func (r *Registry) run(ctx context.Context, name string, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.Timeout)
+	defer cancel()
+
+	start := time.Now()
+	details, err := c.fn(ctx)
+
+	result := Result{
+		Status:   StatusUp,
+		Required: c.required,
+		Duration: time.Since(start).String(),
+		Details:  details,
+	}
+	if err != nil {
+		slog.Error("health check failed", "check", name, "error", err)
+
+		result.Status = StatusDown
+		result.Error = "check failed"
+		if errors.Is(err, context.DeadlineExceeded) {
+			result.Error = "timeout"
+		}
+	}
+
+	return result
+}
+
+func (r *Registry) RegisterRoutes(engine *gin.Engine) {
+	engine.GET("/healthz", r.Liveness)
+	engine.GET("/readyz", r.Readiness)
+}
+
+// Liveness only reports that the process is serving requests.
+func (r *Registry) Liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": StatusUp})
+}
+
+// Readiness responds 503 when any required check fails.
+func (r *Registry) Readiness(ctx *gin.Context) {
+	report := r.Check(ctx.Request.Context())
+
+	status := http.StatusOK
+	if report.Status == StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+
+	ctx.JSON(status, report)
+}
@@ -0,0 +1,106 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func up(ctx context.Context) (map[string]interface{}, error) {
+	return nil, nil
+}
+
+func down(ctx context.Context) (map[string]interface{}, error) {
+	return nil, errors.New("connection refused")
+}
+
+func serve(registry *Registry, path string) (*httptest.ResponseRecorder, Report) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	registry.RegisterRoutes(r)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+	var report Report
+	json.Unmarshal(w.Body.Bytes(), &report)
+
+	return w, report
+}
+
+func TestLiveness(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register("database", true, down)
+
+	w, report := serve(registry, "/healthz")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, StatusUp, report.Status)
+}
+
+func TestReadiness(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register("database", true, up)
+	registry.Register("cache", false, down)
+
+	w, report := serve(registry, "/readyz")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, StatusUp, report.Status)
+	assert.Equal(t, StatusUp, report.Checks["database"].Status)
+	assert.Equal(t, StatusDown, report.Checks["cache"].Status)
+	assert.Equal(t, "check failed", report.Checks["cache"].Error)
+	assert.NotContains(t, w.Body.String(), "connection refused")
+}
+
+func TestReadinessRequiredFailure(t *testing.T) {
+	registry := NewRegistry(time.Second)
+	registry.Register("database", true, down)
+
+	w, report := serve(registry, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, StatusDown, report.Status)
+}
+
+func TestReadinessTimeout(t *testing.T) {
+	registry := NewRegistry(50 * time.Millisecond)
+	registry.Register("slow", true, func(ctx context.Context) (map[string]interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	w, report := serve(registry, "/readyz")
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "timeout", report.Checks["slow"].Error)
+}
+
+func TestDatabase(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectPing()
+	mock.ExpectPing().WillReturnError(errors.New("connection refused"))
+
+	check := Database(sqlx.NewDb(db, "sqlmock"))
+
+	details, err := check(context.Background())
+	assert.NoError(t, err)
+	assert.Contains(t, details, "open_connections")
+
+	details, err = check(context.Background())
+	assert.EqualError(t, err, "connection refused")
+	assert.Contains(t, details, "in_use")
+}
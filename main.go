@@ -1,25 +1,63 @@
 package main
 
 import (
+	"log"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"api_boilerplate/config"
 	"api_boilerplate/db"
+	"api_boilerplate/health"
+	"api_boilerplate/problem"
+	"api_boilerplate/repository"
+	"api_boilerplate/server"
 	"api_boilerplate/util"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalln("Invalid configuration: ", err)
+	}
+
+	level, _ := cfg.Level()
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	gin.SetMode(cfg.Server.GinMode)
 	r := gin.New()
-	gin.SetMode(gin.ReleaseMode)
-	r.Use(gin.Recovery())
+	r.Use(gin.CustomRecovery(problem.Recovery))
 
 	r.GET("/", func(c *gin.Context) {
 		c.String(200, "Health")
 	})
 
-	dbConn := db.GetDBConnection()
-	defer dbConn.Close()
+	dbConn := db.GetDBConnection(cfg.Database)
+
+	checks := health.NewRegistry(time.Duration(cfg.Health.Timeout))
+	checks.Register("database", true, health.Database(dbConn))
+	checks.RegisterRoutes(r)
+
+	uow := repository.NewUnitOfWork(dbConn)
+	uow.Isolation, _ = cfg.Database.Isolation()
+
+	util.RegisterDomains(r, uow, cfg)
+
+	srv := &http.Server{
+		Addr:         cfg.Server.Addr,
+		Handler:      r,
+		ReadTimeout:  time.Duration(cfg.Server.ReadTimeout),
+		WriteTimeout: time.Duration(cfg.Server.WriteTimeout),
+		IdleTimeout:  time.Duration(cfg.Server.IdleTimeout),
+	}
 
-	util.RegisterDomains(r, dbConn)
+	slog.Info("listening", "addr", cfg.Server.Addr)
 
-	r.Run("0.0.0.0:3030")
+	// The pool is closed only after in-flight requests have drained.
+	if err := server.Run(srv, time.Duration(cfg.Server.DrainTimeout), dbConn); err != nil {
+		log.Fatalln("Server error: ", err)
+	}
 }
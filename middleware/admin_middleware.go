@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"api_boilerplate/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware only lets through requests carrying token as a bearer
+// token in the Authorization header.
+func AdminMiddleware(token string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		given, ok := strings.CutPrefix(ctx.GetHeader("Authorization"), "Bearer ")
+		if !ok || token == "" || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			ctx.Header("WWW-Authenticate", "Bearer")
+			problem.Write(ctx, problem.New(http.StatusUnauthorized, "a valid admin token is required"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/admin", AdminMiddleware("secret"), func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	for header, status := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"secret":        http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Bearer secret": http.StatusNoContent,
+	} {
+		req, _ := http.NewRequest("GET", "/admin", nil)
+		req.Header.Set("Authorization", header)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		assert.Equal(t, status, resp.Code, header)
+	}
+}
@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+func FieldsMiddleware(fields []string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		selected, err := parseFields(ctx.Query("fields"), fields)
+		if err != nil {
+			problem.Respond(ctx, apperror.Validation(nil, "%s", err.Error()))
+			return
+		}
+
+		ctx.Set("fields", selected)
+
+		ctx.Next()
+	}
+}
+
+// parseFields validates a "id,name,price" projection. An empty value
+// selects every field and yields nil.
+func parseFields(raw string, fields []string) ([]string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var selected []string
+	for _, part := range strings.Split(raw, ",") {
+		field := strings.TrimSpace(part)
+
+		if !slices.Contains(fields, field) {
+			return nil, fmt.Errorf("invalid field %q", field)
+		}
+		if !slices.Contains(selected, field) {
+			selected = append(selected, field)
+		}
+	}
+
+	return selected, nil
+}
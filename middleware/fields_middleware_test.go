@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var projectionFields = []string{"id", "name", "price"}
+
+func TestParseFields(t *testing.T) {
+	selected, err := parseFields("name, price,name", projectionFields)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name", "price"}, selected)
+
+	selected, err = parseFields("", projectionFields)
+
+	assert.NoError(t, err)
+	assert.Nil(t, selected)
+}
+
+func TestParseFieldsInvalid(t *testing.T) {
+	for _, raw := range []string{"password", "name,", "*", "name FROM user --"} {
+		_, err := parseFields(raw, projectionFields)
+		assert.Error(t, err, raw)
+	}
+}
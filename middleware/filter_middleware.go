@@ -3,15 +3,47 @@ package middleware
 import (
 	"fmt"
 	"net/url"
+	"slices"
 	"strings"
 
+	"api_boilerplate/apperror"
+	"api_boilerplate/dialect"
+	"api_boilerplate/problem"
+
 	"github.com/gin-gonic/gin"
 )
 
-func FilterMiddleware() gin.HandlerFunc {
+var FilterOperators = []string{
+	"eql", "neq", "gt", "gte", "lt", "lte",
+	"lik", "starts", "ends",
+	"in", "nin", "between",
+	"isnull", "notnull",
+}
+
+var comparisonOperators = map[string]string{
+	"eql": "=",
+	"neq": "<>",
+	"gt":  ">",
+	"gte": ">=",
+	"lt":  "<",
+	"lte": "<=",
+}
+
+// orParam holds OR groups: or=name:lik:foo|email:lik:foo
+const orParam = "or"
+
+// reservedParams are query parameters consumed by other middlewares.
+var reservedParams = []string{"limit", "offset", "cursor", "sort", "fields", "with_deleted", "only_deleted", orParam, rsqlParam}
+
+// FilterMiddleware renders the filter parameters in the SQL of d.
+func FilterMiddleware(fields []string, d dialect.Dialect) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		queryParams := ctx.Request.URL.Query()
-		queryStr, filters := parseFilters(queryParams)
+		queryStr, filters, err := parseFilters(queryParams, fields, d)
+		if err != nil {
+			problem.Respond(ctx, apperror.Validation(nil, "%s", err.Error()))
+			return
+		}
 
 		ctx.Set("filtersQuery", filters)
 		ctx.Set("filtersSQL", queryStr)
@@ -20,35 +52,184 @@ func FilterMiddleware() gin.HandlerFunc {
 	}
 }
 
-func parseFilters(filters url.Values) (string, map[string]interface{}) {
-	queryFilter := ""
-	filtersValues := map[string]interface{}{}
+// parseFilters builds a parameterized WHERE clause. Keys are only ever
+// interpolated after being matched against fields; values are always bound.
+func parseFilters(filters url.Values, fields []string, d dialect.Dialect) (string, map[string]interface{}, error) {
+	builder := newConditionBuilder(fields, d)
+	var conditions []string
 
-	for key, value := range filters {
-		split := strings.Split(value[0], ",")
-		if len(split) < 2 {
+	keys := make([]string, 0, len(filters))
+	for key := range filters {
+		if slices.Contains(reservedParams, key) {
 			continue
 		}
 
-		op := split[0]
-		val := split[1]
+		if !slices.Contains(fields, key) {
+			return "", nil, fmt.Errorf("unknown filter field %q", key)
+		}
+
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		for _, filter := range filters[key] {
+			split := strings.SplitN(filter, ",", 2)
+
+			condition, err := builder.condition(key, split[0], split[1:])
+			if err != nil {
+				return "", nil, err
+			}
+
+			conditions = append(conditions, condition)
+		}
+	}
+
+	for _, group := range filters[orParam] {
+		condition, err := parseOrGroup(builder, group)
+		if err != nil {
+			return "", nil, err
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	for _, expression := range filters[rsqlParam] {
+		condition, err := parseRSQL(expression, builder)
+		if err != nil {
+			return "", nil, err
+		}
+
+		conditions = append(conditions, condition)
+	}
+
+	if len(conditions) == 0 {
+		return "", builder.values, nil
+	}
+
+	return "WHERE " + strings.Join(conditions, " AND "), builder.values, nil
+}
+
+// parseOrGroup renders "field:op:value|field:op:value" as a parenthesized OR.
+func parseOrGroup(builder *conditionBuilder, group string) (string, error) {
+	var conditions []string
+
+	for _, item := range strings.Split(group, "|") {
+		split := strings.SplitN(item, ":", 3)
+		if len(split) < 2 {
+			return "", fmt.Errorf("invalid or filter %q, expected field:op:value", item)
+		}
+
+		if !slices.Contains(builder.fields, split[0]) {
+			return "", fmt.Errorf("unknown filter field %q", split[0])
+		}
 
-		if len(queryFilter) == 0 {
-			queryFilter = "WHERE "
-		} else {
-			queryFilter += " AND "
+		condition, err := builder.condition(split[0], split[1], split[2:])
+		if err != nil {
+			return "", err
 		}
 
-		switch op {
-		case "eql":
-			queryFilter += fmt.Sprintf("%s = :%s", key, key)
-			filtersValues[key] = val
-		case "lik":
-			queryFilter += fmt.Sprintf("%s LIKE :%s", key, key)
-			filtersValues[key] = "%" + val + "%"
-		default:
+		conditions = append(conditions, condition)
+	}
+
+	return "(" + strings.Join(conditions, " OR ") + ")", nil
+}
+
+// conditionBuilder renders single conditions and collects their bound values,
+// giving every placeholder a unique name so one field can appear many times.
+type conditionBuilder struct {
+	fields  []string
+	dialect dialect.Dialect
+	values  map[string]interface{}
+	count   int
+}
+
+func newConditionBuilder(fields []string, d dialect.Dialect) *conditionBuilder {
+	return &conditionBuilder{fields: fields, dialect: d, values: map[string]interface{}{}}
+}
+
+func (b *conditionBuilder) quote(key string) string {
+	return b.dialect.Quote(key)
+}
+
+func (b *conditionBuilder) bind(key string, value interface{}) string {
+	b.count++
+	name := fmt.Sprintf("%s_%d", key, b.count)
+	b.values[name] = value
+
+	return ":" + name
+}
+
+func (b *conditionBuilder) in(key string, values []string, negate bool) string {
+	var params []string
+	for _, value := range values {
+		params = append(params, b.bind(key, value))
+	}
+
+	keyword := "IN"
+	if negate {
+		keyword = "NOT IN"
+	}
+
+	return fmt.Sprintf("%s %s (%s)", b.quote(key), keyword, strings.Join(params, ", "))
+}
+
+// like matches pattern, whose literal parts must be escaped with escapeLike.
+func (b *conditionBuilder) like(key string, pattern string, negate bool) string {
+	return b.dialect.Like(b.quote(key), b.bind(key, pattern), negate)
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes the LIKE wildcards and the escape character in value
+// match literally.
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// condition renders a single "op,value" filter. value holds at most one
+// element: everything after the first separator, if any.
+func (b *conditionBuilder) condition(key string, op string, value []string) (string, error) {
+	switch op {
+	case "isnull":
+		return fmt.Sprintf("%s IS NULL", b.quote(key)), nil
+	case "notnull":
+		return fmt.Sprintf("%s IS NOT NULL", b.quote(key)), nil
+	}
+
+	if slices.Contains(FilterOperators, op) && len(value) == 0 {
+		return "", fmt.Errorf("filter %q: operator %q requires a value", key, op)
+	}
+
+	var val string
+	if len(value) > 0 {
+		val = value[0]
+	}
+
+	switch op {
+	case "eql", "neq", "gt", "gte", "lt", "lte":
+		return fmt.Sprintf("%s %s %s", b.quote(key), comparisonOperators[op], b.bind(key, val)), nil
+	case "lik":
+		return b.like(key, "%"+escapeLike(val)+"%", false), nil
+	case "starts":
+		return b.like(key, escapeLike(val)+"%", false), nil
+	case "ends":
+		return b.like(key, "%"+escapeLike(val), false), nil
+	case "in", "nin":
+		return b.in(key, strings.Split(val, ","), op == "nin"), nil
+	case "between":
+		bounds := strings.Split(val, ",")
+		if len(bounds) != 2 || bounds[0] == "" || bounds[1] == "" {
+			return "", fmt.Errorf("filter %q: operator \"between\" requires exactly two values", key)
 		}
+
+		return fmt.Sprintf("%s BETWEEN %s AND %s", b.quote(key), b.bind(key, bounds[0]), b.bind(key, bounds[1])), nil
 	}
 
-	return queryFilter, filtersValues
+	return "", fmt.Errorf(
+		"filter %q: unknown operator %q, valid operators are: %s",
+		key,
+		op,
+		strings.Join(FilterOperators, ", "),
+	)
 }
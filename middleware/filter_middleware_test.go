@@ -0,0 +1,227 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"api_boilerplate/dialect"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+var filterFields = []string{"id", "name", "email", "age", "created_at", "updated_at"}
+
+// noQuote leaves columns bare so the expected SQL stays readable.
+func noQuote(column string) string {
+	return column
+}
+
+// bareDialect is SQLite with bare columns, for the same reason.
+type bareDialect struct {
+	dialect.SQLite
+}
+
+func (bareDialect) Quote(column string) string {
+	return noQuote(column)
+}
+
+var bare = bareDialect{}
+
+// safeWhere matches clauses built solely from allowlisted columns and named placeholders.
+var safeWhere = regexp.MustCompile(`^(WHERE [a-z_]+ (=|LIKE) :[a-z_]+_\d+( ESCAPE '\\')?( AND [a-z_]+ (=|LIKE) :[a-z_]+_\d+( ESCAPE '\\')?)*)?$`)
+
+func TestParseFilters(t *testing.T) {
+	sql, values, err := parseFilters(url.Values{
+		"name":  {"lik,john"},
+		"email": {"eql,john@mail.com"},
+		"limit": {"10"},
+		"sort":  {"-name,id"},
+	}, filterFields, bare)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "WHERE email = :email_1 AND name LIKE :name_2 ESCAPE '\\'", sql)
+	assert.Equal(t, map[string]interface{}{"email_1": "john@mail.com", "name_2": "%john%"}, values)
+}
+
+func TestParseFiltersQuotesColumns(t *testing.T) {
+	sql, _, err := parseFilters(url.Values{
+		"order": {"in,1,2"},
+		"or":    {"key:isnull|order:eql:3"},
+	}, []string{"order", "key"}, dialect.Postgres{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `WHERE "order" IN (:order_1, :order_2) AND ("key" IS NULL OR "order" = :order_3)`, sql)
+}
+
+func TestParseFiltersOperators(t *testing.T) {
+	cases := []struct {
+		filter string
+		sql    string
+		values map[string]interface{}
+	}{
+		{"eql,10", "WHERE age = :age_1", map[string]interface{}{"age_1": "10"}},
+		{"neq,10", "WHERE age <> :age_1", map[string]interface{}{"age_1": "10"}},
+		{"gt,10", "WHERE age > :age_1", map[string]interface{}{"age_1": "10"}},
+		{"gte,10", "WHERE age >= :age_1", map[string]interface{}{"age_1": "10"}},
+		{"lt,10", "WHERE age < :age_1", map[string]interface{}{"age_1": "10"}},
+		{"lte,10", "WHERE age <= :age_1", map[string]interface{}{"age_1": "10"}},
+		{"lik,1", "WHERE age LIKE :age_1 ESCAPE '\\'", map[string]interface{}{"age_1": "%1%"}},
+		{"starts,1", "WHERE age LIKE :age_1 ESCAPE '\\'", map[string]interface{}{"age_1": "1%"}},
+		{"ends,1", "WHERE age LIKE :age_1 ESCAPE '\\'", map[string]interface{}{"age_1": "%1"}},
+		{"lik,50%_off", "WHERE age LIKE :age_1 ESCAPE '\\'", map[string]interface{}{"age_1": `%50\%\_off%`}},
+		{"starts,_a\\b", "WHERE age LIKE :age_1 ESCAPE '\\'", map[string]interface{}{"age_1": `\_a\\b%`}},
+		{"ends,100%", "WHERE age LIKE :age_1 ESCAPE '\\'", map[string]interface{}{"age_1": `%100\%`}},
+		{
+			"in,18,21,30",
+			"WHERE age IN (:age_1, :age_2, :age_3)",
+			map[string]interface{}{"age_1": "18", "age_2": "21", "age_3": "30"},
+		},
+		{
+			"nin,18,21",
+			"WHERE age NOT IN (:age_1, :age_2)",
+			map[string]interface{}{"age_1": "18", "age_2": "21"},
+		},
+		{
+			"between,10,50",
+			"WHERE age BETWEEN :age_1 AND :age_2",
+			map[string]interface{}{"age_1": "10", "age_2": "50"},
+		},
+		{"isnull", "WHERE age IS NULL", map[string]interface{}{}},
+		{"notnull", "WHERE age IS NOT NULL", map[string]interface{}{}},
+	}
+
+	for _, c := range cases {
+		sql, values, err := parseFilters(url.Values{"age": {c.filter}}, filterFields, bare)
+
+		assert.NoError(t, err, c.filter)
+		assert.Equal(t, c.sql, sql, c.filter)
+		assert.Equal(t, c.values, values, c.filter)
+	}
+}
+
+func TestParseFiltersBetweenDates(t *testing.T) {
+	sql, values, err := parseFilters(url.Values{"created_at": {"between,2025-01-01,2025-01-31 23:59:59"}}, filterFields, bare)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "WHERE created_at BETWEEN :created_at_1 AND :created_at_2", sql)
+	assert.Equal(t, "2025-01-31 23:59:59", values["created_at_2"])
+}
+
+func TestParseFiltersInvalidOperators(t *testing.T) {
+	for _, filter := range []string{"foo,1", "eql", "between,1", "between,1,2,3", "between,,2", "x", "EQL,1"} {
+		_, _, err := parseFilters(url.Values{"age": {filter}}, filterFields, bare)
+		assert.Error(t, err, filter)
+	}
+
+	_, _, err := parseFilters(url.Values{"age": {"foo,1"}}, filterFields, bare)
+	assert.ErrorContains(t, err, "valid operators are: eql, neq")
+}
+
+func TestParseFiltersRepeatedKeys(t *testing.T) {
+	sql, values, err := parseFilters(url.Values{"age": {"gte,18", "lt,65"}}, filterFields, bare)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "WHERE age >= :age_1 AND age < :age_2", sql)
+	assert.Equal(t, map[string]interface{}{"age_1": "18", "age_2": "65"}, values)
+}
+
+func TestParseFiltersOrGroups(t *testing.T) {
+	sql, values, err := parseFilters(url.Values{
+		"age": {"gte,18"},
+		"or":  {"name:lik:foo|email:lik:foo", "created_at:isnull|created_at:gt:2025-01-01 00:00:00"},
+	}, filterFields, bare)
+
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		"WHERE age >= :age_1 AND (name LIKE :name_2 ESCAPE '\\' OR email LIKE :email_3 ESCAPE '\\') "+
+			"AND (created_at IS NULL OR created_at > :created_at_4)",
+		sql,
+	)
+	assert.Equal(t, map[string]interface{}{
+		"age_1":        "18",
+		"name_2":       "%foo%",
+		"email_3":      "%foo%",
+		"created_at_4": "2025-01-01 00:00:00",
+	}, values)
+}
+
+func TestParseFiltersInvalidOrGroups(t *testing.T) {
+	groups := []string{
+		"name",
+		"name:lik:foo|",
+		"password:eql:x|name:eql:x",
+		"name:foo:x",
+		"1=1;--:eql:x",
+	}
+
+	for _, group := range groups {
+		_, _, err := parseFilters(url.Values{"or": {group}}, filterFields, bare)
+		assert.Error(t, err, group)
+	}
+}
+
+func TestParseFiltersRejectsUnknownFields(t *testing.T) {
+	keys := []string{
+		"1=1;DROP TABLE user;--",
+		"name OR 1=1",
+		"name;--",
+		"name`",
+		"NAME",
+		"(SELECT password FROM admin)",
+		"name = name AND 1",
+		"password",
+		"",
+	}
+
+	for _, key := range keys {
+		sql, values, err := parseFilters(url.Values{key: {"eql,x"}}, filterFields, bare)
+
+		assert.Error(t, err, key)
+		assert.Empty(t, sql, key)
+		assert.Nil(t, values, key)
+	}
+}
+
+func TestParseFiltersBindsMaliciousValues(t *testing.T) {
+	values := []string{
+		"' OR '1'='1",
+		"x'; DROP TABLE user; --",
+		"1 UNION SELECT * FROM user",
+		"a,b,c",
+		"%' OR name LIKE '%",
+		"\\'; --",
+		":email",
+	}
+
+	for _, value := range values {
+		for op, bound := range map[string]string{"eql": value, "lik": escapeLike(value)} {
+			sql, params, err := parseFilters(url.Values{"name": {op + "," + value}}, filterFields, bare)
+
+			assert.NoError(t, err, value)
+			assert.Regexp(t, safeWhere, sql, value)
+			assert.NotContains(t, sql, value, value)
+			assert.Contains(t, params["name_1"], bound, value)
+		}
+	}
+}
+
+func TestFilterMiddlewareRejectsUnknownFields(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", FilterMiddleware(filterFields, bare), func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	query := url.Values{"1=1;DROP TABLE user;--": {"eql,x"}}.Encode()
+	req, _ := http.NewRequest("GET", "/?"+query, nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, 400, resp.Code)
+	assert.Contains(t, resp.Body.String(), "unknown filter field")
+}
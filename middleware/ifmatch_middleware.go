@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"api_boilerplate/apperror"
+	"api_boilerplate/problem"
+	"api_boilerplate/versioning"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IfMatchMiddleware stores the version of the If-Match header in the request
+// context, where the repository checks it. Without the header the request
+// fails with 428 when required is set.
+func IfMatchMiddleware(required bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader("If-Match")
+		if header == "" {
+			if required {
+				problem.Respond(ctx, apperror.PreconditionRequired(nil, "the If-Match header is required"))
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+
+		versions, any, err := versioning.ParseIfMatch(header)
+		if err != nil {
+			problem.Respond(ctx, apperror.Validation(err, "%s", err.Error()))
+			return
+		}
+
+		if !any {
+			ctx.Request = ctx.Request.WithContext(versioning.WithExpected(ctx.Request.Context(), versions))
+		}
+
+		ctx.Next()
+	}
+}
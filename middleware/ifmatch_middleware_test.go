@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api_boilerplate/versioning"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIfMatchMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.PATCH("/item", IfMatchMiddleware(false), func(ctx *gin.Context) {
+		if versions, ok := versioning.Expected(ctx.Request.Context()); ok {
+			ctx.String(http.StatusOK, "%v", versions)
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		header string
+		status int
+		body   string
+	}{
+		{"", http.StatusNoContent, ""},
+		{"*", http.StatusNoContent, ""},
+		{`"7"`, http.StatusOK, "[7]"},
+		{`"1", "2"`, http.StatusOK, "[1 2]"},
+		{`W/"7"`, http.StatusOK, "[]"},
+		{`7`, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		req, _ := http.NewRequest("PATCH", "/item", nil)
+		req.Header.Set("If-Match", tt.header)
+		resp := httptest.NewRecorder()
+		r.ServeHTTP(resp, req)
+
+		assert.Equal(t, tt.status, resp.Code, tt.header)
+		if tt.body != "" {
+			assert.Equal(t, tt.body, resp.Body.String(), tt.header)
+		}
+	}
+}
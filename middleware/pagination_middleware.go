@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"api_boilerplate/apperror"
+	"api_boilerplate/pagination"
+	"api_boilerplate/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+func PaginationMiddleware(limits pagination.Limits) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		params, err := limits.Parse(ctx.Request.URL.Query())
+		if err != nil {
+			problem.Respond(ctx, apperror.Validation(nil, "%s", err.Error()))
+			return
+		}
+
+		ctx.Set("pagination", params)
+
+		ctx.Next()
+	}
+}
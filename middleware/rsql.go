@@ -0,0 +1,278 @@
+package middleware
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// rsqlParam holds an RSQL/FIQL expression: filter=name==foo*;(age=gt=18,stock==0)
+const rsqlParam = "filter"
+
+// rsqlOperators maps RSQL comparison operators to filter operators.
+var rsqlOperators = map[string]string{
+	"==":     "eql",
+	"!=":     "neq",
+	"=gt=":   "gt",
+	">":      "gt",
+	"=ge=":   "gte",
+	">=":     "gte",
+	"=lt=":   "lt",
+	"<":      "lt",
+	"=le=":   "lte",
+	"<=":     "lte",
+	"=in=":   "in",
+	"=out=":  "nin",
+	"=null=": "null",
+}
+
+// rsqlReserved are the characters that cannot appear in unquoted selectors or values.
+const rsqlReserved = "\"'();,=!~<> "
+
+// parseRSQL renders an RSQL expression into a WHERE condition using builder,
+// so placeholders are shared with the other filters of the same request.
+//
+//	expression = or
+//	or         = and *( "," and )
+//	and        = constraint *( ";" constraint )
+//	constraint = "(" or ")" / selector operator arguments
+//	arguments  = "(" value *( "," value ) ")" / value
+func parseRSQL(input string, builder *conditionBuilder) (string, error) {
+	p := &rsqlParser{input: input, builder: builder}
+
+	condition, err := p.parseOr()
+	if err != nil {
+		return "", err
+	}
+
+	if !p.done() {
+		return "", p.errorf("unexpected %q", p.input[p.pos])
+	}
+
+	return condition, nil
+}
+
+type rsqlParser struct {
+	input   string
+	pos     int
+	builder *conditionBuilder
+}
+
+func (p *rsqlParser) done() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *rsqlParser) peek(b byte) bool {
+	return !p.done() && p.input[p.pos] == b
+}
+
+func (p *rsqlParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid filter at position %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *rsqlParser) parseOr() (string, error) {
+	return p.parseList(',', " OR ", p.parseAnd)
+}
+
+func (p *rsqlParser) parseAnd() (string, error) {
+	return p.parseList(';', " AND ", p.parseConstraint)
+}
+
+func (p *rsqlParser) parseList(separator byte, join string, parse func() (string, error)) (string, error) {
+	var conditions []string
+
+	for {
+		condition, err := parse()
+		if err != nil {
+			return "", err
+		}
+		conditions = append(conditions, condition)
+
+		if !p.peek(separator) {
+			break
+		}
+		p.pos++
+	}
+
+	if len(conditions) == 1 {
+		return conditions[0], nil
+	}
+
+	return "(" + strings.Join(conditions, join) + ")", nil
+}
+
+func (p *rsqlParser) parseConstraint() (string, error) {
+	if !p.peek('(') {
+		return p.parseComparison()
+	}
+	p.pos++
+
+	condition, err := p.parseOr()
+	if err != nil {
+		return "", err
+	}
+
+	if !p.peek(')') {
+		return "", p.errorf("expected \")\"")
+	}
+	p.pos++
+
+	return condition, nil
+}
+
+func (p *rsqlParser) parseComparison() (string, error) {
+	start := p.pos
+	selector := p.readUnreserved()
+	if selector == "" {
+		return "", p.errorf("expected field name")
+	}
+
+	if !slices.Contains(p.builder.fields, selector) {
+		p.pos = start
+		return "", p.errorf("unknown filter field %q", selector)
+	}
+
+	op, err := p.parseOperator()
+	if err != nil {
+		return "", err
+	}
+
+	args, err := p.parseArguments()
+	if err != nil {
+		return "", err
+	}
+
+	switch op {
+	case "in", "nin":
+		return p.builder.in(selector, args, op == "nin"), nil
+	}
+
+	if len(args) != 1 {
+		return "", p.errorf("operator for %q accepts a single value", selector)
+	}
+	value := args[0]
+
+	switch op {
+	case "null":
+		switch value {
+		case "true":
+			return p.builder.condition(selector, "isnull", nil)
+		case "false":
+			return p.builder.condition(selector, "notnull", nil)
+		}
+
+		return "", p.errorf("=null= expects true or false")
+	case "eql", "neq":
+		// "*" is the RSQL wildcard for equality comparisons; the LIKE
+		// wildcards in the value are escaped first so they match literally.
+		if strings.Contains(value, "*") {
+			return p.builder.like(selector, strings.ReplaceAll(escapeLike(value), "*", "%"), op == "neq"), nil
+		}
+	}
+
+	return p.builder.condition(selector, op, []string{value})
+}
+
+func (p *rsqlParser) parseOperator() (string, error) {
+	start := p.pos
+	rest := p.input[p.pos:]
+
+	var op string
+	switch {
+	case strings.HasPrefix(rest, "=="), strings.HasPrefix(rest, "!="),
+		strings.HasPrefix(rest, ">="), strings.HasPrefix(rest, "<="):
+		op = rest[:2]
+	case strings.HasPrefix(rest, ">"), strings.HasPrefix(rest, "<"):
+		op = rest[:1]
+	case strings.HasPrefix(rest, "="):
+		end := strings.IndexByte(rest[1:], '=')
+		if end > 0 {
+			op = rest[:end+2]
+		}
+	}
+
+	mapped, ok := rsqlOperators[op]
+	if !ok {
+		return "", p.errorf("expected comparison operator")
+	}
+
+	p.pos = start + len(op)
+	return mapped, nil
+}
+
+func (p *rsqlParser) parseArguments() ([]string, error) {
+	if !p.peek('(') {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{value}, nil
+	}
+	p.pos++
+
+	var values []string
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		if p.peek(')') {
+			p.pos++
+			return values, nil
+		}
+		if !p.peek(',') {
+			return nil, p.errorf("expected \",\" or \")\"")
+		}
+		p.pos++
+	}
+}
+
+func (p *rsqlParser) parseValue() (string, error) {
+	if p.peek('"') || p.peek('\'') {
+		return p.parseQuoted()
+	}
+
+	value := p.readUnreserved()
+	if value == "" {
+		return "", p.errorf("expected value")
+	}
+
+	return value, nil
+}
+
+func (p *rsqlParser) parseQuoted() (string, error) {
+	quote := p.input[p.pos]
+	start := p.pos
+	p.pos++
+
+	var value strings.Builder
+	for !p.done() {
+		c := p.input[p.pos]
+		p.pos++
+
+		switch {
+		case c == '\\' && !p.done():
+			value.WriteByte(p.input[p.pos])
+			p.pos++
+		case c == quote:
+			return value.String(), nil
+		default:
+			value.WriteByte(c)
+		}
+	}
+
+	p.pos = start
+	return "", p.errorf("unterminated quoted value")
+}
+
+func (p *rsqlParser) readUnreserved() string {
+	start := p.pos
+	for !p.done() && !strings.ContainsRune(rsqlReserved, rune(p.input[p.pos])) {
+		p.pos++
+	}
+
+	return p.input[start:p.pos]
+}
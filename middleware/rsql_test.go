@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var rsqlFields = []string{"id", "name", "age", "stock", "created_at"}
+
+func TestParseRSQL(t *testing.T) {
+	cases := []struct {
+		expression string
+		sql        string
+		values     map[string]interface{}
+	}{
+		{
+			"name==foo*;(age=gt=18,stock==0)",
+			"(name LIKE :name_1 ESCAPE '\\' AND (age > :age_2 OR stock = :stock_3))",
+			map[string]interface{}{"name_1": "foo%", "age_2": "18", "stock_3": "0"},
+		},
+		{"name==foo", "name = :name_1", map[string]interface{}{"name_1": "foo"}},
+		{"name!=*foo*", "name NOT LIKE :name_1 ESCAPE '\\'", map[string]interface{}{"name_1": "%foo%"}},
+		{"name==*50%_off", "name LIKE :name_1 ESCAPE '\\'", map[string]interface{}{"name_1": `%50\%\_off`}},
+		{"age>=18", "age >= :age_1", map[string]interface{}{"age_1": "18"}},
+		{"age<65", "age < :age_1", map[string]interface{}{"age_1": "65"}},
+		{"age=le=65", "age <= :age_1", map[string]interface{}{"age_1": "65"}},
+		{
+			"age=in=(18,21)",
+			"age IN (:age_1, :age_2)",
+			map[string]interface{}{"age_1": "18", "age_2": "21"},
+		},
+		{
+			"name=out=('a,b',\"c\\\"d\")",
+			"name NOT IN (:name_1, :name_2)",
+			map[string]interface{}{"name_1": "a,b", "name_2": "c\"d"},
+		},
+		{"name=='John Doe'", "name = :name_1", map[string]interface{}{"name_1": "John Doe"}},
+		{"created_at=null=true", "created_at IS NULL", map[string]interface{}{}},
+		{"created_at=null=false,age==1", "(created_at IS NOT NULL OR age = :age_1)", map[string]interface{}{"age_1": "1"}},
+	}
+
+	for _, c := range cases {
+		builder := newConditionBuilder(rsqlFields, bare)
+		sql, err := parseRSQL(c.expression, builder)
+
+		assert.NoError(t, err, c.expression)
+		assert.Equal(t, c.sql, sql, c.expression)
+		assert.Equal(t, c.values, builder.values, c.expression)
+	}
+}
+
+func TestParseRSQLErrors(t *testing.T) {
+	cases := map[string]string{
+		"":                           "position 0: expected field name",
+		"name":                       "position 4: expected comparison operator",
+		"name=~foo":                  "position 4: expected comparison operator",
+		"name==":                     "position 6: expected value",
+		"password==x":                "position 0: unknown filter field \"password\"",
+		"(name==a":                   "position 8: expected \")\"",
+		"name==a)":                   "position 7: unexpected ')'",
+		"name=='abc":                 "position 6: unterminated quoted value",
+		"age=in=(1,2":                "position 11: expected \",\" or \")\"",
+		"age=gt=(1,2)":               "accepts a single value",
+		"created_at=null=maybe":      "=null= expects true or false",
+		"name==a;1=1;DROP TABLE x==": "position 8: unknown filter field \"1\"",
+	}
+
+	for expression, message := range cases {
+		_, err := parseRSQL(expression, newConditionBuilder(rsqlFields, bare))
+		assert.ErrorContains(t, err, message, expression)
+	}
+}
+
+func TestParseFiltersWithRSQL(t *testing.T) {
+	sql, values, err := parseFilters(url.Values{
+		"age":    {"gte,18"},
+		"filter": {"name==foo*,name==bar*"},
+	}, rsqlFields, bare)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "WHERE age >= :age_1 AND (name LIKE :name_2 ESCAPE '\\' OR name LIKE :name_3 ESCAPE '\\')", sql)
+	assert.Equal(t, map[string]interface{}{"age_1": "18", "name_2": "foo%", "name_3": "bar%"}, values)
+}
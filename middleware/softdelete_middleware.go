@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"api_boilerplate/apperror"
+	"api_boilerplate/problem"
+	"api_boilerplate/softdelete"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SoftDeleteMiddleware stores the scope requested by with_deleted and
+// only_deleted in the request context, where the repository reads it.
+func SoftDeleteMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		scope, err := softdelete.Parse(ctx.Request.URL.Query())
+		if err != nil {
+			problem.Respond(ctx, apperror.Validation(err, "%s", err.Error()))
+			return
+		}
+
+		ctx.Request = ctx.Request.WithContext(softdelete.WithScope(ctx.Request.Context(), scope))
+
+		ctx.Next()
+	}
+}
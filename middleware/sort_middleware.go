@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/problem"
+
+	"github.com/gin-gonic/gin"
+)
+
+var ErrSortWithCursor = errors.New("sort cannot be combined with cursor pagination")
+
+// SortMiddleware renders the sort parameter, quoting columns with quote.
+func SortMiddleware(fields []string, quote func(string) string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		queryParams := ctx.Request.URL.Query()
+
+		sortSQL, err := parseSort(queryParams.Get("sort"), fields, quote)
+		if err == nil && sortSQL != "" && queryParams.Get("cursor") != "" {
+			err = ErrSortWithCursor
+		}
+		if err != nil {
+			problem.Respond(ctx, apperror.Validation(nil, "%s", err.Error()))
+			return
+		}
+
+		ctx.Set("sortSQL", sortSQL)
+
+		ctx.Next()
+	}
+}
+
+// parseSort turns "-created_at,name" into "ORDER BY created_at DESC, name ASC",
+// accepting only columns present in fields.
+func parseSort(sort string, fields []string, quote func(string) string) (string, error) {
+	if strings.TrimSpace(sort) == "" {
+		return "", nil
+	}
+
+	var clauses []string
+	var seen []string
+
+	for _, part := range strings.Split(sort, ",") {
+		field := strings.TrimSpace(part)
+		direction := "ASC"
+
+		if strings.HasPrefix(field, "-") {
+			field = field[1:]
+			direction = "DESC"
+		}
+
+		if !slices.Contains(fields, field) {
+			return "", fmt.Errorf("invalid sort field %q", field)
+		}
+		if slices.Contains(seen, field) {
+			return "", fmt.Errorf("duplicate sort field %q", field)
+		}
+		seen = append(seen, field)
+
+		clauses = append(clauses, fmt.Sprintf("%s %s", quote(field), direction))
+	}
+
+	return "ORDER BY " + strings.Join(clauses, ", "), nil
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"testing"
+
+	"api_boilerplate/dialect"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var sortFields = []string{"id", "name", "created_at"}
+
+func TestParseSort(t *testing.T) {
+	sql, err := parseSort("-created_at, name", sortFields, noQuote)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDER BY created_at DESC, name ASC", sql)
+
+	sql, err = parseSort("", sortFields, noQuote)
+
+	assert.NoError(t, err)
+	assert.Empty(t, sql)
+}
+
+func TestParseSortQuotesColumns(t *testing.T) {
+	sql, err := parseSort("-order,key", []string{"order", "key"}, dialect.MySQL{}.Quote)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "ORDER BY `order` DESC, `key` ASC", sql)
+}
+
+func TestParseSortInvalid(t *testing.T) {
+	for _, sort := range []string{"price", "name;DROP TABLE user", "-", "name,,id", "name,-name"} {
+		_, err := parseSort(sort, sortFields, noQuote)
+		assert.Error(t, err, sort)
+	}
+}
@@ -2,9 +2,9 @@ package model
 
 type Product struct {
 	ID        string  `json:"id" db:"id"`
-	Name      string  `json:"name" db:"name"`
-	Price     float64 `json:"price" db:"price"`
-	Stock     int     `json:"stock" db:"stock"`
+	Name      string  `json:"name" db:"name" validate:"required,max=255"`
+	Price     float64 `json:"price" db:"price" validate:"gte=0"`
+	Stock     int     `json:"stock" db:"stock" validate:"gte=0"`
 	CreatedAt string  `json:"created_at" db:"created_at"`
 	UpdatedAt string  `json:"updated_at" db:"updated_at"`
 }
@@ -2,8 +2,8 @@ package model
 
 type Store struct {
 	ID          string `json:"id" db:"id"`
-	Name        string `json:"name" db:"name"`
-	Description string `json:"description" db:"description"`
+	Name        string `json:"name" db:"name" validate:"required,max=255"`
+	Description string `json:"description" db:"description" validate:"max=255"`
 	CreatedAt   string `json:"created_at" db:"created_at"`
 	UpdatedAt   string `json:"updated_at" db:"updated_at"`
 }
@@ -2,9 +2,9 @@ package model
 
 type User struct {
 	ID        string  `json:"id" db:"id"`
-	Name      string  `json:"name" db:"name"`
-	Email     string  `json:"email" db:"email"`
-	Age       int     `json:"age" db:"age"`
+	Name      string  `json:"name" db:"name" validate:"required,max=255"`
+	Email     string  `json:"email" db:"email" validate:"required,email,max=255"`
+	Age       int     `json:"age" db:"age" validate:"gte=0,lte=150"`
 	CreatedAt *string `json:"created_at" db:"created_at"`
 	UpdatedAt *string `json:"updated_at" db:"updated_at"`
 }
@@ -0,0 +1,84 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	DefaultLimit = 20
+	MaxLimit     = 100
+)
+
+var (
+	ErrInvalidLimit  = errors.New("invalid limit")
+	ErrInvalidOffset = errors.New("offset must be a non-negative integer")
+	ErrInvalidCursor = errors.New("cursor must be a valid ULID")
+	ErrCursorOffset  = errors.New("cursor and offset cannot be used together")
+)
+
+// Limits bounds the page size a resource accepts.
+type Limits struct {
+	Default int
+	Max     int
+}
+
+var DefaultLimits = Limits{Default: DefaultLimit, Max: MaxLimit}
+
+// Params describes the requested page. When Cursor is set the page is
+// fetched by keyset (id > cursor) instead of by offset.
+type Params struct {
+	Limit  int
+	Offset int
+	Cursor string
+}
+
+// Page is the envelope returned by list endpoints.
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int    `json:"total"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+func Parse(values url.Values) (Params, error) {
+	return DefaultLimits.Parse(values)
+}
+
+func (l Limits) Parse(values url.Values) (Params, error) {
+	params := Params{Limit: l.Default}
+
+	if raw := values.Get("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 || limit > l.Max {
+			return params, fmt.Errorf("%w: must be an integer between 1 and %d", ErrInvalidLimit, l.Max)
+		}
+		params.Limit = limit
+	}
+
+	if raw := values.Get("offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 {
+			return params, ErrInvalidOffset
+		}
+		params.Offset = offset
+	}
+
+	if raw := values.Get("cursor"); raw != "" {
+		if params.Offset > 0 {
+			return params, ErrCursorOffset
+		}
+		if _, err := ulid.ParseStrict(raw); err != nil {
+			return params, ErrInvalidCursor
+		}
+		params.Cursor = raw
+	}
+
+	return params, nil
+}
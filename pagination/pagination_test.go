@@ -0,0 +1,55 @@
+package pagination
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDefaults(t *testing.T) {
+	params, err := Parse(url.Values{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, Params{Limit: DefaultLimit}, params)
+}
+
+func TestParse(t *testing.T) {
+	params, err := Parse(url.Values{"limit": {"10"}, "offset": {"30"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, Params{Limit: 10, Offset: 30}, params)
+
+	params, err = Parse(url.Values{"limit": {"10"}, "cursor": {"01JW4MH8S671QVVGD0NYY1XWAP"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, Params{Limit: 10, Cursor: "01JW4MH8S671QVVGD0NYY1XWAP"}, params)
+}
+
+func TestParseInvalid(t *testing.T) {
+	cases := map[string]url.Values{
+		"zero limit":      {"limit": {"0"}},
+		"limit too big":   {"limit": {"101"}},
+		"limit not int":   {"limit": {"ten"}},
+		"negative offset": {"offset": {"-1"}},
+		"invalid cursor":  {"cursor": {"not-a-ulid"}},
+		"cursor + offset": {"offset": {"10"}, "cursor": {"01JW4MH8S671QVVGD0NYY1XWAP"}},
+	}
+
+	for name, values := range cases {
+		_, err := Parse(values)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestLimitsParse(t *testing.T) {
+	limits := Limits{Default: 5, Max: 10}
+
+	params, err := limits.Parse(url.Values{})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, params.Limit)
+
+	_, err = limits.Parse(url.Values{"limit": {"11"}})
+	assert.ErrorIs(t, err, ErrInvalidLimit)
+	assert.EqualError(t, err, "invalid limit: must be an integer between 1 and 10")
+}
@@ -0,0 +1,103 @@
+package problem
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"api_boilerplate/apperror"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document.
+type Problem struct {
+	Type     string                `json:"type"`
+	Title    string                `json:"title"`
+	Status   int                   `json:"status"`
+	Detail   string                `json:"detail,omitempty"`
+	Instance string                `json:"instance,omitempty"`
+	Errors   []apperror.FieldError `json:"errors,omitempty"`
+}
+
+var kinds = []struct {
+	kind     error
+	status   int
+	typeName string
+}{
+	{apperror.ErrValidation, http.StatusBadRequest, "/problems/validation"},
+	{apperror.ErrForbidden, http.StatusForbidden, "/problems/forbidden"},
+	{apperror.ErrNotFound, http.StatusNotFound, "/problems/not-found"},
+	{apperror.ErrConflict, http.StatusConflict, "/problems/conflict"},
+	{apperror.ErrPreconditionFailed, http.StatusPreconditionFailed, "/problems/precondition-failed"},
+	{apperror.ErrPreconditionRequired, http.StatusPreconditionRequired, "/problems/precondition-required"},
+	{apperror.ErrTimeout, http.StatusGatewayTimeout, "/problems/timeout"},
+	{apperror.ErrCanceled, StatusClientClosedRequest, "/problems/canceled"},
+}
+
+// StatusClientClosedRequest is the non-standard status, borrowed from nginx,
+// of requests whose client went away before the response.
+const StatusClientClosedRequest = 499
+
+func New(status int, detail string) Problem {
+	title := http.StatusText(status)
+	if status == StatusClientClosedRequest {
+		title = "Client Closed Request"
+	}
+
+	return Problem{
+		Type:   "about:blank",
+		Title:  title,
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// FromError builds the problem for err. Only apperror messages reach the
+// client; anything else is reported as an opaque internal error.
+func FromError(err error) Problem {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		for _, k := range kinds {
+			if errors.Is(err, k.kind) {
+				p := New(k.status, appErr.Message)
+				p.Type = k.typeName
+				p.Errors = appErr.Fields
+
+				return p
+			}
+		}
+	}
+
+	return New(http.StatusInternalServerError, "an unexpected error occurred")
+}
+
+// Write aborts the request with p.
+func Write(ctx *gin.Context, p Problem) {
+	if p.Instance == "" {
+		p.Instance = ctx.Request.URL.Path
+	}
+
+	ctx.Header("Content-Type", ContentType)
+	ctx.AbortWithStatusJSON(p.Status, p)
+}
+
+// Respond renders err as a problem. Internal errors are logged since their
+// detail is hidden from the client.
+func Respond(ctx *gin.Context, err error) {
+	p := FromError(err)
+	if p.Status == http.StatusInternalServerError {
+		slog.Error("request failed", "method", ctx.Request.Method, "path", ctx.Request.URL.Path, "error", err)
+	}
+
+	_ = ctx.Error(err)
+	Write(ctx, p)
+}
+
+// Recovery renders panics as problems; use it with gin.CustomRecovery.
+func Recovery(ctx *gin.Context, recovered any) {
+	Respond(ctx, fmt.Errorf("panic: %v", recovered))
+}
@@ -0,0 +1,103 @@
+package problem
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"api_boilerplate/apperror"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromError(t *testing.T) {
+	p := FromError(apperror.NotFound(sql.ErrNoRows, "user %s not found", "01JW4MH8S671QVVGD0NYY1XWAP"))
+
+	assert.Equal(t, Problem{
+		Type:   "/problems/not-found",
+		Title:  "Not Found",
+		Status: 404,
+		Detail: "user 01JW4MH8S671QVVGD0NYY1XWAP not found",
+	}, p)
+}
+
+func TestFromErrorValidationFields(t *testing.T) {
+	err := apperror.Validation(nil, "invalid user").WithFields(
+		apperror.FieldError{Field: "email", Message: "must be a valid email"},
+	)
+
+	p := FromError(err)
+
+	assert.Equal(t, 400, p.Status)
+	assert.Equal(t, "/problems/validation", p.Type)
+	assert.Equal(t, []apperror.FieldError{{Field: "email", Message: "must be a valid email"}}, p.Errors)
+}
+
+func TestFromErrorInternal(t *testing.T) {
+	p := FromError(errors.New("dial tcp 127.0.0.1:3306: connect: connection refused"))
+
+	assert.Equal(t, 500, p.Status)
+	assert.Equal(t, "about:blank", p.Type)
+	assert.NotContains(t, p.Detail, "3306")
+}
+
+func TestRespond(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(gin.CustomRecovery(Recovery))
+	r.GET("/conflict", func(ctx *gin.Context) {
+		Respond(ctx, apperror.Conflict(nil, "user already exists"))
+	})
+	r.GET("/panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	req, _ := http.NewRequest("GET", "/conflict", nil)
+	resp := httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, 409, resp.Code)
+	assert.Equal(t, ContentType, resp.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "/problems/conflict",
+		"title": "Conflict",
+		"status": 409,
+		"detail": "user already exists",
+		"instance": "/conflict"
+	}`, resp.Body.String())
+
+	req, _ = http.NewRequest("GET", "/panic", nil)
+	resp = httptest.NewRecorder()
+	r.ServeHTTP(resp, req)
+
+	assert.Equal(t, 500, resp.Code)
+	assert.Equal(t, ContentType, resp.Header().Get("Content-Type"))
+	assert.NotContains(t, resp.Body.String(), "boom")
+}
+
+func TestFromErrorTimeout(t *testing.T) {
+	p := FromError(apperror.Timeout(context.DeadlineExceeded, "query timed out"))
+
+	assert.Equal(t, 504, p.Status)
+	assert.Equal(t, "/problems/timeout", p.Type)
+}
+
+func TestFromErrorCanceled(t *testing.T) {
+	p := FromError(apperror.Canceled(context.Canceled, "query canceled"))
+
+	assert.Equal(t, StatusClientClosedRequest, p.Status)
+	assert.Equal(t, "Client Closed Request", p.Title)
+}
+
+func TestFromErrorPrecondition(t *testing.T) {
+	p := FromError(apperror.PreconditionFailed(nil, "version mismatch"))
+	assert.Equal(t, 412, p.Status)
+	assert.Equal(t, "/problems/precondition-failed", p.Type)
+
+	p = FromError(apperror.PreconditionRequired(nil, "If-Match is required"))
+	assert.Equal(t, 428, p.Status)
+}
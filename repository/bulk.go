@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"api_boilerplate/dialect"
+	"api_boilerplate/versioning"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// DefaultBatchSize is the number of rows per multi-row INSERT or DELETE.
+const DefaultBatchSize = 500
+
+func (r *SqlxRepository[T]) batchSize(columns int) int {
+	size := r.BatchSize
+	if size <= 0 {
+		size = DefaultBatchSize
+	}
+
+	if columns > 0 && size*columns > r.Dialect.MaxParams() {
+		size = r.Dialect.MaxParams() / columns
+	}
+
+	return size
+}
+
+// CreateMany inserts items with multi-row INSERT statements of up to
+// BatchSize rows. Run it inside a transaction to make it all-or-nothing.
+func (r *SqlxRepository[T]) CreateMany(ctx context.Context, items []T) ([]T, error) {
+	created := make([]T, 0, len(items))
+	rows := make([]map[string]interface{}, 0, len(items))
+
+	now := r.Dialect.Timestamp(time.Now())
+	for _, item := range items {
+		dataMap, err := r.convertToMap(item)
+		if err != nil {
+			return nil, err
+		}
+
+		dataMap["id"] = ulid.Make().String()
+		dataMap["created_at"] = now
+		dataMap["updated_at"] = now
+		if r.Versioned {
+			dataMap[versioning.Column] = 1
+		}
+
+		rows = append(rows, dataMap)
+	}
+
+	size := r.batchSize(len(r.insertColumns()))
+	for start := 0; start < len(rows); start += size {
+		batch := rows[start:min(start+size, len(rows))]
+
+		if err := r.insertBatch(ctx, batch); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, dataMap := range rows {
+		item, err := r.convertFromMap(dataMap)
+		if err != nil {
+			return nil, err
+		}
+
+		created = append(created, item)
+	}
+
+	return created, nil
+}
+
+func (r *SqlxRepository[T]) insertBatch(ctx context.Context, rows []map[string]interface{}) error {
+	columns := r.insertColumns()
+	placeholders := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ") + ")"
+
+	values := make([]string, 0, len(rows))
+	args := make([]interface{}, 0, len(rows)*len(columns))
+	for _, row := range rows {
+		values = append(values, placeholders)
+		for _, column := range columns {
+			args = append(args, row[column])
+		}
+	}
+
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES %s",
+		r.Dialect.Quote(r.TableName),
+		strings.Join(dialect.QuoteAll(r.Dialect, columns), ", "),
+		strings.Join(values, ", "),
+	)
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.conn(ctx).ExecContext(ctx, dialect.Rebind(r.Dialect, query), args...)
+	return r.translateError(err, "")
+}
+
+// DeleteMany deletes, or soft-deletes, the rows with the given ids and
+// returns how many existed.
+func (r *SqlxRepository[T]) DeleteMany(ctx context.Context, ids []string) (int64, error) {
+	var deleted int64
+
+	size := r.batchSize(1)
+	for start := 0; start < len(ids); start += size {
+		batch := ids[start:min(start+size, len(ids))]
+
+		if r.SoftDelete {
+			affected, err := r.softDelete(ctx, batch)
+			if err != nil {
+				return deleted, err
+			}
+
+			deleted += affected
+			continue
+		}
+
+		query := fmt.Sprintf(
+			"DELETE FROM %s WHERE %s IN (%s)",
+			r.Dialect.Quote(r.TableName),
+			r.Dialect.Quote("id"),
+			strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", "),
+		)
+
+		args := make([]interface{}, len(batch))
+		for i, id := range batch {
+			args[i] = id
+		}
+
+		affected, err := r.deleteBatch(ctx, query, args)
+		if err != nil {
+			return deleted, err
+		}
+
+		deleted += affected
+	}
+
+	return deleted, nil
+}
+
+func (r *SqlxRepository[T]) deleteBatch(ctx context.Context, query string, args []interface{}) (int64, error) {
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.conn(ctx).ExecContext(ctx, dialect.Rebind(r.Dialect, query), args...)
+	if err != nil {
+		return 0, r.translateError(err, "")
+	}
+
+	return result.RowsAffected()
+}
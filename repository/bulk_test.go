@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/dialect"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatchSize(t *testing.T) {
+	repo := &SqlxRepository[sqliteModel]{Dialect: dialect.SQLite{}}
+	assert.Equal(t, DefaultBatchSize, repo.batchSize(5))
+
+	repo.BatchSize = 10000
+	assert.Equal(t, dialect.SQLite{}.MaxParams()/5, repo.batchSize(5))
+}
+
+func TestSQLiteCreateManyAndDeleteMany(t *testing.T) {
+	repo := setupSQLite(t)
+	repo.BatchSize = 2
+
+	items := make([]sqliteModel, 5)
+	for i := range items {
+		items[i] = sqliteModel{Name: fmt.Sprint("item ", i), Price: float64(i)}
+	}
+
+	created, err := repo.CreateMany(t.Context(), items)
+	require.NoError(t, err)
+	require.Len(t, created, 5)
+	assert.NotEmpty(t, created[4].ID)
+	assert.NotNil(t, created[4].CreatedAt)
+	assert.Equal(t, 5, countRows(t, repo))
+
+	deleted, err := repo.DeleteMany(t.Context(), []string{created[0].ID, created[1].ID, created[2].ID, "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.Equal(t, 2, countRows(t, repo))
+}
+
+func TestSQLiteCreateManyWithinTxRollback(t *testing.T) {
+	uow, repo := setupUnitOfWork(t)
+	repo.BatchSize = 1
+
+	err := uow.WithinTx(t.Context(), func(ctx context.Context) error {
+		_, err := repo.CreateMany(ctx, []sqliteModel{{Name: "Pen"}, {Name: "Pencil"}, {Name: "Pen"}})
+		return err
+	})
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	assert.Zero(t, countRows(t, repo))
+}
+
+func TestWithinTxNestsInSavepoint(t *testing.T) {
+	uow, repo := setupUnitOfWork(t)
+
+	err := uow.WithinTx(t.Context(), func(ctx context.Context) error {
+		if _, err := repo.Create(ctx, sqliteModel{Name: "Pen"}); err != nil {
+			return err
+		}
+
+		inner := uow.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := repo.Create(ctx, sqliteModel{Name: "Pen"})
+			return err
+		})
+		assert.ErrorIs(t, inner, apperror.ErrConflict)
+
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countRows(t, repo))
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"api_boilerplate/apperror"
+)
+
+// translateError maps driver errors to apperror kinds so callers never
+// depend on database specifics.
+func (r *SqlxRepository[T]) translateError(err error, id string) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return apperror.Timeout(err, "%s query timed out", r.TableName)
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return apperror.Canceled(err, "%s query canceled", r.TableName)
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return apperror.NotFound(err, "%s %s not found", r.TableName, id)
+	}
+
+	if r.Dialect.IsDuplicateKey(err) {
+		return apperror.Conflict(err, "%s with the same unique value already exists", r.TableName)
+	}
+
+	return err
+}
+
+// execResult reports statements that matched no row as not found.
+func (r *SqlxRepository[T]) execResult(result sql.Result, err error, id string) error {
+	if err != nil {
+		return r.translateError(err, id)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if affected == 0 {
+		return r.translateError(sql.ErrNoRows, id)
+	}
+
+	return nil
+}
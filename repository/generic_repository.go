@@ -1,37 +1,153 @@
 package repository
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
 	"strings"
 	"time"
 
+	"api_boilerplate/apperror"
+	"api_boilerplate/dialect"
+	"api_boilerplate/pagination"
+	"api_boilerplate/softdelete"
+	"api_boilerplate/versioning"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/oklog/ulid/v2"
 )
 
 type SqlxRepository[T any] struct {
 	DB        *sqlx.DB
+	Dialect   dialect.Dialect
 	TableName string
 	Fields    []string
+	// QueryTimeout bounds every statement; zero leaves only the caller's deadline.
+	QueryTimeout time.Duration
+	// BatchSize caps the rows per bulk statement; see DefaultBatchSize.
+	BatchSize int
+	// SoftDelete makes Delete stamp the deleted_at column instead of removing
+	// rows. Deleted rows are hidden unless the context scope shows them.
+	SoftDelete bool
+	// Versioned maintains a version column, incremented by every write, and
+	// makes writes fail when it differs from the version expected by the
+	// context.
+	Versioned bool
+
+	tx *Tx
 }
 
+// NewSqlxRepository picks the SQL dialect from the driver db was opened with.
 func NewSqlxRepository[T any](
 	db *sqlx.DB,
 	table string,
 	fields []string,
 ) *SqlxRepository[T] {
-	return &SqlxRepository[T]{DB: db, TableName: table, Fields: fields}
+	return &SqlxRepository[T]{
+		DB:        db,
+		Dialect:   dialect.ForDriver(db.DriverName()),
+		TableName: table,
+		Fields:    fields,
+	}
+}
+
+// WithTx returns a copy of the repository that runs its statements in tx.
+func (r *SqlxRepository[T]) WithTx(tx *Tx) *SqlxRepository[T] {
+	bound := *r
+	bound.tx = tx
+	return &bound
+}
+
+// conn returns the transaction the repository is bound to, or the one
+// carried by ctx (see UnitOfWork.WithinTx), or the connection pool.
+func (r *SqlxRepository[T]) conn(ctx context.Context) executor {
+	if r.tx != nil {
+		return r.tx
+	}
+
+	if tx, ok := ctx.Value(txKey{}).(*Tx); ok && tx.uow.DB == r.DB {
+		return tx
+	}
+
+	return r.DB
+}
+
+// withTimeout applies QueryTimeout to ctx.
+func (r *SqlxRepository[T]) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if r.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, r.QueryTimeout)
 }
 
-func (r *SqlxRepository[T]) FindAll(query string, filtersQuery map[string]interface{}) ([]T, error) {
-	var items []T
+func (r *SqlxRepository[T]) FindAll(
+	ctx context.Context,
+	query string,
+	filtersQuery map[string]interface{},
+	sort string,
+	fields []string,
+	page pagination.Params,
+) (pagination.Page[T], error) {
+	if page.Limit <= 0 {
+		page.Limit = pagination.DefaultLimit
+	}
+
+	result := pagination.Page[T]{Items: []T{}, Limit: page.Limit, Offset: page.Offset}
+
+	if condition := r.scope(ctx); condition != "" {
+		query = appendCondition(query, condition)
+	}
 
-	finalQuery := fmt.Sprintf("SELECT * FROM %s %s", r.TableName, query)
-	rows, err := r.DB.NamedQuery(finalQuery, filtersQuery)
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
 
+	total, err := r.count(ctx, query, filtersQuery)
 	if err != nil {
-		return items, err
+		return result, r.translateError(err, "")
+	}
+	result.Total = total
+
+	params := make(map[string]interface{}, len(filtersQuery)+1)
+	for k, v := range filtersQuery {
+		params[k] = v
+	}
+
+	id := r.Dialect.Quote("id")
+
+	where := query
+	if page.Cursor != "" {
+		where = appendCondition(where, id+" > :_cursor")
+		params["_cursor"] = page.Cursor
+	}
+
+	// id is always the last sort key so pages are stable for equal values.
+	orderBy := "ORDER BY " + id
+	if sort != "" {
+		orderBy = sort + ", " + id
+	}
+
+	// One extra row is fetched to know whether another page exists.
+	finalQuery := strings.Join(nonEmpty(
+		fmt.Sprintf("SELECT %s FROM %s", r.columns(fields), r.Dialect.Quote(r.TableName)),
+		where,
+		orderBy,
+		r.Dialect.LimitOffset(page.Limit+1, page.Offset),
+	), " ")
+
+	boundQuery, args, err := r.named(finalQuery, params)
+	if err != nil {
+		return result, err
+	}
+
+	rows, err := r.conn(ctx).QueryxContext(ctx, boundQuery, args...)
+
+	if err != nil {
+		return result, r.translateError(err, "")
 	}
 	defer rows.Close()
 
@@ -40,40 +156,180 @@ func (r *SqlxRepository[T]) FindAll(query string, filtersQuery map[string]interf
 
 		err = rows.StructScan(&item)
 		if err != nil {
-			break
+			return result, r.translateError(err, "")
 		}
 
-		items = append(items, item)
+		result.Items = append(result.Items, item)
+	}
+
+	if len(result.Items) > page.Limit {
+		result.Items = result.Items[:page.Limit]
+		result.HasMore = true
+
+		// Keyset pagination only follows the natural id order.
+		if sort == "" {
+			result.NextCursor = r.idOf(result.Items[page.Limit-1])
+		}
 	}
 
-	return items, err
+	return result, r.translateError(rows.Err(), "")
 }
 
-func (r *SqlxRepository[T]) FindByID(id string) (T, error) {
-	var item T
-	err := r.DB.Get(&item, fmt.Sprintf("SELECT * FROM %s WHERE id = ?", r.TableName), id)
-	return item, err
+func (r *SqlxRepository[T]) count(ctx context.Context, query string, filtersQuery map[string]interface{}) (int, error) {
+	var total int
+
+	countQuery, args, err := r.named(
+		strings.Join(nonEmpty(fmt.Sprintf("SELECT COUNT(*) FROM %s", r.Dialect.Quote(r.TableName)), query), " "),
+		filtersQuery,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	err = sqlx.GetContext(ctx, r.conn(ctx), &total, countQuery, args...)
+	return total, err
+}
+
+// named compiles a query with :name parameters into the dialect's bind style.
+func (r *SqlxRepository[T]) named(query string, arg interface{}) (string, []interface{}, error) {
+	bound, args, err := sqlx.Named(query, arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return dialect.Rebind(r.Dialect, bound), args, nil
+}
+
+// exec runs a statement with :name parameters.
+func (r *SqlxRepository[T]) exec(ctx context.Context, query string, arg interface{}) (sql.Result, error) {
+	bound, args, err := r.named(query, arg)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	return r.conn(ctx).ExecContext(ctx, bound, args...)
+}
+
+func (r *SqlxRepository[T]) idOf(item T) string {
+	field, ok := r.DB.Mapper.FieldMap(reflect.ValueOf(&item))["id"]
+	if !ok {
+		return ""
+	}
+
+	return fmt.Sprint(field.Interface())
 }
 
-func (r *SqlxRepository[T]) Create(item T) error {
-	fields := strings.Join(r.Fields, ", ")
-	values := strings.Join(r.Fields, ", :")
+// columns renders the select list for a projection. id is always selected
+// since pagination cursors depend on it.
+func (r *SqlxRepository[T]) columns(fields []string) string {
+	if len(fields) == 0 {
+		return "*"
+	}
+
+	if !slices.Contains(fields, "id") {
+		fields = append([]string{"id"}, fields...)
+	}
+
+	// The version is always selected since it is the entity tag.
+	if r.Versioned && !slices.Contains(fields, versioning.Column) {
+		fields = append(fields, versioning.Column)
+	}
+
+	return strings.Join(dialect.QuoteAll(r.Dialect, fields), ", ")
+}
+
+// setClauses renders "col = :col" assignments for the given columns.
+func (r *SqlxRepository[T]) setClauses(fields []string) string {
+	var setClauses []string
+	for _, f := range fields {
+		setClauses = append(setClauses, fmt.Sprintf("%s = :%s", r.Dialect.Quote(f), f))
+	}
+
+	return strings.Join(setClauses, ", ")
+}
+
+func appendCondition(where string, condition string) string {
+	if strings.TrimSpace(where) == "" {
+		return "WHERE " + condition
+	}
 
+	return where + " AND " + condition
+}
+
+func nonEmpty(parts ...string) []string {
+	var result []string
+	for _, part := range parts {
+		if strings.TrimSpace(part) != "" {
+			result = append(result, part)
+		}
+	}
+
+	return result
+}
+
+func (r *SqlxRepository[T]) FindByID(ctx context.Context, id string, fields []string) (T, error) {
+	return r.FindBy(ctx, "id", id, fields)
+}
+
+// FindBy returns the entity whose key column equals value.
+func (r *SqlxRepository[T]) FindBy(ctx context.Context, key string, value string, fields []string) (T, error) {
+	var item T
+
+	if key != "id" && !slices.Contains(r.Fields, key) {
+		return item, fmt.Errorf("%s is not a field of %s", key, r.TableName)
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s = ?",
+		r.columns(fields),
+		r.Dialect.Quote(r.TableName),
+		r.Dialect.Quote(key),
+	)
+	if condition := r.scope(ctx); condition != "" {
+		query = appendCondition(query, condition)
+	}
+
+	err := sqlx.GetContext(ctx, r.conn(ctx), &item, dialect.Rebind(r.Dialect, query), value)
+	return item, r.translateError(err, value)
+}
+
+// Create inserts item with a new ULID and timestamps, returning the
+// persisted entity.
+func (r *SqlxRepository[T]) Create(ctx context.Context, item T) (T, error) {
 	dataMap, err := r.convertToMap(item)
 	if err != nil {
-		return err
+		return item, err
 	}
 
 	id := ulid.Make()
+	now := r.Dialect.Timestamp(time.Now())
 
 	dataMap["id"] = id.String()
-	dataMap["created_at"] = time.Now().Format("2006-01-02 15:04:05")
-	dataMap["updated_at"] = time.Now().Format("2006-01-02 15:04:05")
+	dataMap["created_at"] = now
+	dataMap["updated_at"] = now
+	if r.Versioned {
+		dataMap[versioning.Column] = 1
+	}
+
+	columns := r.insertColumns()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (:%s)",
+		r.Dialect.Quote(r.TableName),
+		strings.Join(dialect.QuoteAll(r.Dialect, columns), ", "),
+		strings.Join(columns, ", :"),
+	)
 
-	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (:%s)", r.TableName, fields, values)
+	if _, err = r.exec(ctx, query, dataMap); err != nil {
+		return item, r.translateError(err, id.String())
+	}
 
-	_, err = r.DB.NamedExec(query, dataMap)
-	return err
+	return r.convertFromMap(dataMap)
 }
 
 func (r *SqlxRepository[T]) convertToMap(item T) (map[string]interface{}, error) {
@@ -91,37 +347,175 @@ func (r *SqlxRepository[T]) convertToMap(item T) (map[string]interface{}, error)
 	return result, nil
 }
 
-func (r *SqlxRepository[T]) Update(id string, item T) error {
-	setClauses := generateQueryFields(r.Fields)
+func (r *SqlxRepository[T]) convertFromMap(dataMap map[string]interface{}) (T, error) {
+	var item T
+
+	data, err := json.Marshal(dataMap)
+	if err != nil {
+		return item, err
+	}
 
-	query := fmt.Sprintf("UPDATE %s SET %s WHERE id = :id", r.TableName, setClauses)
+	err = json.Unmarshal(data, &item)
+	return item, err
+}
+
+// Update overwrites all fields of the row except id and created_at.
+func (r *SqlxRepository[T]) Update(ctx context.Context, id string, item T) error {
+	var fields []string
+	for _, f := range r.Fields {
+		if f != "id" && f != "created_at" {
+			fields = append(fields, f)
+		}
+	}
 
 	dataMap, err := r.convertToMap(item)
 	if err != nil {
 		return err
 	}
 
-	dataMap["updated_at"] = time.Now().Format("2006-01-02 15:04:05")
+	dataMap["id"] = id
+	dataMap["updated_at"] = r.Dialect.Timestamp(time.Now())
+
+	result, err := r.exec(ctx, r.updateQuery(ctx, fields, dataMap), dataMap)
+
+	return r.mismatch(ctx, id, r.execResult(result, err, id))
+}
+
+// Upsert inserts item or, when a row with the same key column exists,
+// overwrites all its fields except id and created_at, returning the stored
+// entity. key must be the primary key or have a unique index. Run it in a
+// transaction: MySQL resolves conflicts on any unique index, and an upsert
+// that hit a different row is reported as a conflict to be rolled back.
+func (r *SqlxRepository[T]) Upsert(ctx context.Context, key string, item T) (T, error) {
+	dataMap, err := r.convertToMap(item)
+	if err != nil {
+		return item, err
+	}
+
+	value := dataMap[key]
+	if value == nil || value == "" {
+		return item, apperror.Validation(nil, "%s is required", key)
+	}
+
+	if id, _ := dataMap["id"].(string); id == "" {
+		dataMap["id"] = ulid.Make().String()
+	}
+
+	if err := r.checkVersion(ctx, key, value); err != nil {
+		return item, err
+	}
+
+	now := r.Dialect.Timestamp(time.Now())
+	dataMap["created_at"] = now
+	dataMap["updated_at"] = now
+	if r.Versioned {
+		dataMap[versioning.Column] = 1
+	}
 
-	_, err = r.DB.NamedExec(query, dataMap)
+	var update []string
+	for _, f := range r.Fields {
+		if f != "id" && f != "created_at" && f != key {
+			update = append(update, f)
+		}
+	}
 
-	return err
+	columns := r.insertColumns()
+	if r.SoftDelete {
+		// Upserting a soft-deleted row restores it.
+		columns = append(slices.Clone(columns), softdelete.Column)
+		update = append(update, softdelete.Column)
+		dataMap[softdelete.Column] = nil
+	}
+
+	var increment []string
+	if r.Versioned {
+		increment = append(increment, versioning.Column)
+	}
+
+	query := r.Dialect.Upsert(r.TableName, columns, []string{key}, update, increment)
+	if _, err := r.exec(ctx, query, dataMap); err != nil {
+		return item, r.translateError(err, fmt.Sprint(value))
+	}
+
+	stored, err := r.FindBy(ctx, key, fmt.Sprint(value), nil)
+	if errors.Is(err, apperror.ErrNotFound) {
+		return stored, apperror.Conflict(nil, "%s %v conflicts with another %s on a unique value", r.TableName, value, r.TableName)
+	}
+
+	return stored, err
 }
 
-func (r *SqlxRepository[T]) Delete(id string) error {
-	_, err := r.DB.Exec(fmt.Sprintf("DELETE FROM %s WHERE id = ?", r.TableName), id)
-	return err
+// updateQuery renders an UPDATE of fields by :id, adding the expected
+// version to dataMap when ctx carries one.
+func (r *SqlxRepository[T]) updateQuery(ctx context.Context, fields []string, dataMap map[string]interface{}) string {
+	where := r.active("WHERE " + r.Dialect.Quote("id") + " = :id")
+	name := func(i int) string { return fmt.Sprintf("_version_%d", i) }
+	if condition, versions, ok := r.expected(ctx, func(i int) string { return ":" + name(i) }); ok {
+		where += " AND " + condition
+		for i, version := range versions {
+			dataMap[name(i)] = version
+		}
+	}
+
+	return fmt.Sprintf(
+		"UPDATE %s SET %s%s %s",
+		r.Dialect.Quote(r.TableName),
+		r.setClauses(fields),
+		r.bump(),
+		where,
+	)
 }
 
-func generateQueryFields(fields []string) string {
-	var setClauses []string
-	for _, f := range fields {
-		if f == "id" {
+// Patch updates only the given columns. Keys that are not updatable
+// fields of the resource are ignored.
+func (r *SqlxRepository[T]) Patch(ctx context.Context, id string, changes map[string]interface{}) error {
+	var fields []string
+	dataMap := map[string]interface{}{}
+
+	for _, f := range r.Fields {
+		value, ok := changes[f]
+		if !ok || f == "id" || f == "created_at" || f == "updated_at" {
 			continue
 		}
 
-		setClauses = append(setClauses, fmt.Sprintf("%s = :%s", f, f))
+		fields = append(fields, f)
+		dataMap[f] = value
 	}
 
-	return strings.Join(setClauses, ", ")
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if slices.Contains(r.Fields, "updated_at") {
+		fields = append(fields, "updated_at")
+		dataMap["updated_at"] = r.Dialect.Timestamp(time.Now())
+	}
+	dataMap["id"] = id
+
+	result, err := r.exec(ctx, r.updateQuery(ctx, fields, dataMap), dataMap)
+	return r.mismatch(ctx, id, r.execResult(result, err, id))
+}
+
+func (r *SqlxRepository[T]) Delete(ctx context.Context, id string) error {
+	if r.SoftDelete {
+		deleted, err := r.softDelete(ctx, []string{id})
+		if err == nil && deleted == 0 {
+			err = sql.ErrNoRows
+		}
+
+		return r.mismatch(ctx, id, r.translateError(err, id))
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", r.Dialect.Quote(r.TableName), r.Dialect.Quote("id"))
+	args := []interface{}{id}
+	if condition, versions, ok := r.expected(ctx, positional); ok {
+		query += " AND " + condition
+		args = append(args, versions...)
+	}
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.conn(ctx).ExecContext(ctx, dialect.Rebind(r.Dialect, query), args...)
+	return r.mismatch(ctx, id, r.execResult(result, err, id))
 }
@@ -1,17 +1,22 @@
 package repository
 
 import (
+	"database/sql"
 	"regexp"
 	"testing"
 
+	"api_boilerplate/apperror"
+	"api_boilerplate/pagination"
+
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	"github.com/stretchr/testify/assert"
 )
 
 type TestModel struct {
-	ID   string `db:"id"`
-	Name string `db:"name"`
+	ID   string `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
 }
 
 func setupMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
@@ -28,21 +33,108 @@ func TestFindAll(t *testing.T) {
 	filters := map[string]interface{}{"id": "01JW4MH8S671QVVGD0NYY1XWAP"}
 	query := "WHERE id = :id"
 
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `test_table` WHERE id = ?")).
+		WithArgs("01JW4MH8S671QVVGD0NYY1XWAP").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
 	rows := sqlmock.NewRows([]string{"id", "name"}).
 		AddRow("01JW4MH8S671QVVGD0NYY1XWAP", "Item1").
 		AddRow("01JW4MW2JXJQRQXCPP0T8EGPD0", "Item2")
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM test_table WHERE id = ?")).
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `test_table` WHERE id = ? ORDER BY `id` LIMIT 21 OFFSET 0")).
 		WithArgs("01JW4MH8S671QVVGD0NYY1XWAP").
 		WillReturnRows(rows)
 
 	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
-	items, err := repo.FindAll(query, filters)
+	page, err := repo.FindAll(t.Context(), query, filters, "", nil, pagination.Params{Limit: pagination.DefaultLimit})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", page.Items[0].ID)
+	assert.Equal(t, "Item1", page.Items[0].Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindAllPage(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	filters := map[string]interface{}{"name": "Item"}
+	query := "WHERE name = :name"
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `test_table` WHERE name = ?")).
+		WithArgs("Item").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	rows := sqlmock.NewRows([]string{"id", "name"}).
+		AddRow("01JW4MH8S671QVVGD0NYY1XWAP", "Item1").
+		AddRow("01JW4MW2JXJQRQXCPP0T8EGPD0", "Item2")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `test_table` WHERE name = ? ORDER BY `id` LIMIT 21 OFFSET 0")).
+		WithArgs("Item").
+		WillReturnRows(rows)
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	page, err := repo.FindAll(t.Context(), query, filters, "", nil, pagination.Params{Limit: pagination.DefaultLimit})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 2)
+	assert.Equal(t, 2, page.Total)
+	assert.False(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindAllCursor(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	cursor := "01JW4MH8S671QVVGD0NYY1XWAP"
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `test_table`")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+
+	rows := sqlmock.NewRows([]string{"id", "name"}).
+		AddRow("01JW4MW2JXJQRQXCPP0T8EGPD0", "Item2").
+		AddRow("01JW4MZ7T2S6F2M7N1V9H3KQ4D", "Item3")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `test_table` WHERE `id` > ? ORDER BY `id` LIMIT 2 OFFSET 0")).
+		WithArgs(cursor).
+		WillReturnRows(rows)
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	page, err := repo.FindAll(t.Context(), "", map[string]interface{}{}, "", nil, pagination.Params{Limit: 1, Cursor: cursor})
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, 3, page.Total)
+	assert.True(t, page.HasMore)
+	assert.Equal(t, "01JW4MW2JXJQRQXCPP0T8EGPD0", page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindAllSort(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM `test_table`")).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	rows := sqlmock.NewRows([]string{"id", "name"}).
+		AddRow("01JW4MW2JXJQRQXCPP0T8EGPD0", "Item2").
+		AddRow("01JW4MH8S671QVVGD0NYY1XWAP", "Item1")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `test_table` ORDER BY name DESC, `id` LIMIT 2 OFFSET 0")).
+		WillReturnRows(rows)
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	page, err := repo.FindAll(t.Context(), "", map[string]interface{}{}, "ORDER BY name DESC", nil, pagination.Params{Limit: 1})
 
 	assert.NoError(t, err)
-	assert.Len(t, items, 2)
-	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", items[0].ID)
-	assert.Equal(t, "Item1", items[0].Name)
+	assert.Len(t, page.Items, 1)
+	assert.True(t, page.HasMore)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestFindByID(t *testing.T) {
@@ -53,29 +145,51 @@ func TestFindByID(t *testing.T) {
 
 	row := sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "Item1")
 
-	mock.ExpectQuery("SELECT \\* FROM test_table WHERE id = ?").
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `test_table` WHERE `id` = ?")).
 		WithArgs(id).
 		WillReturnRows(row)
 
 	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
-	item, err := repo.FindByID(id)
+	item, err := repo.FindByID(t.Context(), id, nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "01JW1A10MR50EPWW5QW7JKTFJE", item.ID)
 }
 
+func TestFindByIDFields(t *testing.T) {
+	var id string = "01JW1A10MR50EPWW5QW7JKTFJE"
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	row := sqlmock.NewRows([]string{"id", "name"}).AddRow(id, "Item1")
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT `id`, `name` FROM `test_table` WHERE `id` = ?")).
+		WithArgs(id).
+		WillReturnRows(row)
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	item, err := repo.FindByID(t.Context(), id, []string{"name"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Item1", item.Name)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestCreate(t *testing.T) {
 	db, mock := setupMockDB(t)
 	defer db.Close()
 
-	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO test_table (name) VALUES (?)")).
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `test_table` (`name`) VALUES (?)")).
 		WithArgs("Test").
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
-	err := repo.Create(TestModel{Name: "Test"})
+	item, err := repo.Create(t.Context(), TestModel{Name: "Test"})
 
 	assert.NoError(t, err)
+	assert.Equal(t, "Test", item.Name)
+	assert.Len(t, item.ID, 26)
 }
 
 func TestUpdate(t *testing.T) {
@@ -84,14 +198,31 @@ func TestUpdate(t *testing.T) {
 	db, mock := setupMockDB(t)
 	defer db.Close()
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE test_table SET name = ? WHERE id = ?")).
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `test_table` SET `name` = ? WHERE `id` = ?")).
 		WithArgs("Updated", id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
-	err := repo.Update(id, TestModel{ID: id, Name: "Updated"})
+	err := repo.Update(t.Context(), id, TestModel{ID: id, Name: "Updated"})
+
+	assert.NoError(t, err)
+}
+
+func TestPatch(t *testing.T) {
+	var id string = "01JW1A10MR50EPWW5QW7JKTFJE"
+
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `test_table` SET `name` = ? WHERE `id` = ?")).
+		WithArgs("Patched", id).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"id", "name"})
+	err := repo.Patch(t.Context(), id, map[string]interface{}{"id": "other", "name": "Patched", "unknown": 1})
 
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestDelete(t *testing.T) {
@@ -100,12 +231,68 @@ func TestDelete(t *testing.T) {
 	db, mock := setupMockDB(t)
 	defer db.Close()
 
-	mock.ExpectExec("DELETE FROM test_table WHERE id = ?").
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `test_table` WHERE `id` = ?")).
 		WithArgs(id).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
-	err := repo.Delete(id)
+	err := repo.Delete(t.Context(), id)
 
 	assert.NoError(t, err)
 }
+
+func TestFindByIDNotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT * FROM `test_table` WHERE `id` = ?")).
+		WithArgs("missing").
+		WillReturnError(sql.ErrNoRows)
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	_, err := repo.FindByID(t.Context(), "missing", nil)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestCreateDuplicate(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO `test_table` (`name`) VALUES (?)")).
+		WithArgs("Test").
+		WillReturnError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'Test' for key 'name'"})
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	_, err := repo.Create(t.Context(), TestModel{Name: "Test"})
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+}
+
+func TestUpdateNotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE `test_table` SET `name` = ? WHERE `id` = ?")).
+		WithArgs("Updated", "missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	err := repo.Update(t.Context(), "missing", TestModel{ID: "other", Name: "Updated"})
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestDeleteNotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta("DELETE FROM `test_table` WHERE `id` = ?")).
+		WithArgs("missing").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	repo := NewSqlxRepository[TestModel](db, "test_table", []string{"name"})
+	err := repo.Delete(t.Context(), "missing")
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
@@ -0,0 +1,106 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"api_boilerplate/dialect"
+	"api_boilerplate/softdelete"
+)
+
+var ErrNoSoftDelete = errors.New("soft delete is not enabled")
+
+// scope renders the condition hiding or selecting soft-deleted rows for the
+// scope carried by ctx, or "" when every row is visible.
+func (r *SqlxRepository[T]) scope(ctx context.Context) string {
+	if !r.SoftDelete {
+		return ""
+	}
+
+	column := r.Dialect.Quote(softdelete.Column)
+
+	switch softdelete.FromContext(ctx) {
+	case softdelete.WithDeleted:
+		return ""
+	case softdelete.OnlyDeleted:
+		return column + " IS NOT NULL"
+	default:
+		return column + " IS NULL"
+	}
+}
+
+// active restricts a statement to rows that are not soft-deleted.
+func (r *SqlxRepository[T]) active(where string) string {
+	if !r.SoftDelete {
+		return where
+	}
+
+	return where + " AND " + r.Dialect.Quote(softdelete.Column) + " IS NULL"
+}
+
+// softDelete stamps deleted_at on the active rows with the given ids.
+func (r *SqlxRepository[T]) softDelete(ctx context.Context, ids []string) (int64, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s = ?%s WHERE %s IN (%s) AND %s IS NULL",
+		r.Dialect.Quote(r.TableName),
+		r.Dialect.Quote(softdelete.Column),
+		r.bump(),
+		r.Dialect.Quote("id"),
+		strings.TrimSuffix(strings.Repeat("?, ", len(ids)), ", "),
+		r.Dialect.Quote(softdelete.Column),
+	)
+
+	args := make([]interface{}, 0, len(ids)+2)
+	args = append(args, r.Dialect.Timestamp(time.Now()))
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	if condition, versions, ok := r.expected(ctx, positional); ok {
+		query += " AND " + condition
+		args = append(args, versions...)
+	}
+
+	return r.deleteBatch(ctx, query, args)
+}
+
+// Restore clears deleted_at of a soft-deleted row.
+func (r *SqlxRepository[T]) Restore(ctx context.Context, id string) error {
+	if !r.SoftDelete {
+		return ErrNoSoftDelete
+	}
+
+	query := fmt.Sprintf(
+		"UPDATE %s SET %s = NULL%s WHERE %s = ? AND %s IS NOT NULL",
+		r.Dialect.Quote(r.TableName),
+		r.Dialect.Quote(softdelete.Column),
+		r.bump(),
+		r.Dialect.Quote("id"),
+		r.Dialect.Quote(softdelete.Column),
+	)
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.conn(ctx).ExecContext(ctx, dialect.Rebind(r.Dialect, query), id)
+	return r.execResult(result, err, id)
+}
+
+// Purge permanently removes rows soft-deleted before the given time and
+// returns how many were removed.
+func (r *SqlxRepository[T]) Purge(ctx context.Context, before time.Time) (int64, error) {
+	if !r.SoftDelete {
+		return 0, ErrNoSoftDelete
+	}
+
+	query := fmt.Sprintf(
+		"DELETE FROM %s WHERE %s < ?",
+		r.Dialect.Quote(r.TableName),
+		r.Dialect.Quote(softdelete.Column),
+	)
+
+	return r.deleteBatch(ctx, query, []interface{}{r.Dialect.Timestamp(before)})
+}
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/pagination"
+	"api_boilerplate/softdelete"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type softModel struct {
+	ID        string  `db:"id" json:"id"`
+	Name      string  `db:"name" json:"name"`
+	CreatedAt *string `db:"created_at" json:"created_at"`
+	UpdatedAt *string `db:"updated_at" json:"updated_at"`
+	DeletedAt *string `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+func setupSoftDelete(t *testing.T) *SqlxRepository[softModel] {
+	repo := newSQLiteRepo[softModel](t, `CREATE TABLE "note" (
+		"id" TEXT PRIMARY KEY,
+		"name" TEXT NOT NULL UNIQUE,
+		"created_at" TEXT,
+		"updated_at" TEXT,
+		"deleted_at" TEXT
+	)`, "note", []string{"id", "name", "created_at", "updated_at"})
+	repo.SoftDelete = true
+
+	return repo
+}
+
+func list(t *testing.T, ctx context.Context, repo *SqlxRepository[softModel]) []softModel {
+	page, err := repo.FindAll(ctx, "", map[string]interface{}{}, "", nil, pagination.Params{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, page.Total)
+	return page.Items
+}
+
+func TestSoftDelete(t *testing.T) {
+	repo := setupSoftDelete(t)
+
+	kept, err := repo.Create(t.Context(), softModel{Name: "kept"})
+	require.NoError(t, err)
+	removed, err := repo.Create(t.Context(), softModel{Name: "removed"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.Delete(t.Context(), removed.ID))
+	assert.ErrorIs(t, repo.Delete(t.Context(), removed.ID), apperror.ErrNotFound)
+
+	_, err = repo.FindByID(t.Context(), removed.ID, nil)
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	assert.ErrorIs(t, repo.Patch(t.Context(), removed.ID, map[string]interface{}{"name": "x"}), apperror.ErrNotFound)
+
+	assert.Len(t, list(t, t.Context(), repo), 1)
+	assert.Len(t, list(t, softdelete.WithScope(t.Context(), softdelete.WithDeleted), repo), 2)
+
+	deleted := list(t, softdelete.WithScope(t.Context(), softdelete.OnlyDeleted), repo)
+	require.Len(t, deleted, 1)
+	assert.Equal(t, removed.ID, deleted[0].ID)
+	assert.NotNil(t, deleted[0].DeletedAt)
+
+	require.NoError(t, repo.Restore(t.Context(), removed.ID))
+	assert.ErrorIs(t, repo.Restore(t.Context(), kept.ID), apperror.ErrNotFound)
+	assert.Len(t, list(t, t.Context(), repo), 2)
+}
+
+func TestSoftDeletePurge(t *testing.T) {
+	repo := setupSoftDelete(t)
+
+	created, err := repo.CreateMany(t.Context(), []softModel{{Name: "a"}, {Name: "b"}, {Name: "c"}})
+	require.NoError(t, err)
+
+	deleted, err := repo.DeleteMany(t.Context(), []string{created[0].ID, created[1].ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	purged, err := repo.Purge(t.Context(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+
+	purged, err = repo.Purge(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	assert.Len(t, list(t, softdelete.WithScope(t.Context(), softdelete.WithDeleted), repo), 1)
+}
+
+func TestSoftDeleteUpsertRestores(t *testing.T) {
+	repo := setupSoftDelete(t)
+
+	created, err := repo.Create(t.Context(), softModel{Name: "a"})
+	require.NoError(t, err)
+	require.NoError(t, repo.Delete(t.Context(), created.ID))
+
+	restored, err := repo.Upsert(t.Context(), "name", softModel{Name: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, restored.ID)
+	assert.Nil(t, restored.DeletedAt)
+}
+
+func TestPurgeRequiresSoftDelete(t *testing.T) {
+	repo := setupSQLite(t)
+
+	_, err := repo.Purge(t.Context(), time.Now())
+	assert.ErrorIs(t, err, ErrNoSoftDelete)
+	assert.ErrorIs(t, repo.Restore(t.Context(), "id"), ErrNoSoftDelete)
+}
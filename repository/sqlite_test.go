@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/dialect"
+	"api_boilerplate/pagination"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type sqliteModel struct {
+	ID        string  `db:"id" json:"id"`
+	Name      string  `db:"name" json:"name"`
+	Price     float64 `db:"price" json:"price"`
+	CreatedAt *string `db:"created_at" json:"created_at"`
+	UpdatedAt *string `db:"updated_at" json:"updated_at"`
+}
+
+var sqliteFields = []string{"id", "name", "price", "created_at", "updated_at"}
+
+// newSQLiteRepo creates table in a fresh in-memory database with ddl and
+// returns a repository over it.
+func newSQLiteRepo[T any](t *testing.T, ddl string, table string, fields []string) *SqlxRepository[T] {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	// Every connection to :memory: opens a separate database.
+	db.SetMaxOpenConns(1)
+	db.MustExec(ddl)
+
+	repo := NewSqlxRepository[T](db, table, fields)
+	require.IsType(t, dialect.SQLite{}, repo.Dialect)
+
+	return repo
+}
+
+func setupSQLite(t *testing.T) *SqlxRepository[sqliteModel] {
+	return newSQLiteRepo[sqliteModel](t, `CREATE TABLE "product" (
+		"id" TEXT PRIMARY KEY,
+		"name" TEXT NOT NULL UNIQUE,
+		"price" REAL NOT NULL,
+		"created_at" TEXT,
+		"updated_at" TEXT
+	)`, "product", sqliteFields)
+}
+
+func TestSQLiteCRUD(t *testing.T) {
+	repo := setupSQLite(t)
+
+	created, err := repo.Create(t.Context(), sqliteModel{Name: "Pen", Price: 2.5})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.NotNil(t, created.CreatedAt)
+
+	found, err := repo.FindByID(t.Context(), created.ID, []string{"name"})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, found.ID)
+	assert.Equal(t, "Pen", found.Name)
+	assert.Zero(t, found.Price)
+
+	found.Name = "Pencil"
+	found.Price = 1.5
+	require.NoError(t, repo.Update(t.Context(), created.ID, found))
+
+	require.NoError(t, repo.Patch(t.Context(), created.ID, map[string]interface{}{"price": 3.0}))
+
+	found, err = repo.FindByID(t.Context(), created.ID, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Pencil", found.Name)
+	assert.Equal(t, 3.0, found.Price)
+
+	require.NoError(t, repo.Delete(t.Context(), created.ID))
+
+	_, err = repo.FindByID(t.Context(), created.ID, nil)
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	assert.ErrorIs(t, repo.Delete(t.Context(), created.ID), apperror.ErrNotFound)
+}
+
+func TestSQLiteFindAll(t *testing.T) {
+	repo := setupSQLite(t)
+
+	for _, item := range []sqliteModel{{Name: "a", Price: 1}, {Name: "b", Price: 2}, {Name: "c", Price: 3}} {
+		_, err := repo.Create(t.Context(), item)
+		require.NoError(t, err)
+	}
+
+	page, err := repo.FindAll(
+		t.Context(),
+		"WHERE price >= :price_0",
+		map[string]interface{}{"price_0": 2},
+		"ORDER BY price DESC",
+		nil,
+		pagination.Params{Limit: 1},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, 2, page.Total)
+	assert.True(t, page.HasMore)
+	assert.Len(t, page.Items, 1)
+	assert.Equal(t, "c", page.Items[0].Name)
+
+	first, err := repo.FindAll(t.Context(), "", map[string]interface{}{}, "", nil, pagination.Params{Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.Total)
+	assert.NotEmpty(t, first.NextCursor)
+
+	next, err := repo.FindAll(t.Context(), "", map[string]interface{}{}, "", nil, pagination.Params{Limit: 2, Cursor: first.NextCursor})
+	require.NoError(t, err)
+	assert.False(t, next.HasMore)
+	assert.Len(t, next.Items, 1)
+}
+
+// TestSQLiteFindAllLikeEscape checks that wildcards escaped with a
+// backslash match literally.
+func TestSQLiteFindAllLikeEscape(t *testing.T) {
+	repo := setupSQLite(t)
+
+	for _, name := range []string{"50%_off", "50% off", "500ff"} {
+		_, err := repo.Create(t.Context(), sqliteModel{Name: name, Price: 1})
+		require.NoError(t, err)
+	}
+
+	page, err := repo.FindAll(
+		t.Context(),
+		"WHERE "+dialect.SQLite{}.Like(`"name"`, ":name_0", false),
+		map[string]interface{}{"name_0": `50\%\_%`},
+		"",
+		nil,
+		pagination.Params{Limit: 10},
+	)
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "50%_off", page.Items[0].Name)
+}
+
+func TestSQLiteFindAllZeroParams(t *testing.T) {
+	repo := setupSQLite(t)
+
+	_, err := repo.Create(t.Context(), sqliteModel{Name: "a"})
+	require.NoError(t, err)
+
+	page, err := repo.FindAll(t.Context(), "", map[string]interface{}{}, "", nil, pagination.Params{})
+	require.NoError(t, err)
+	assert.Equal(t, pagination.DefaultLimit, page.Limit)
+	assert.Len(t, page.Items, 1)
+}
+
+func TestSQLiteDuplicate(t *testing.T) {
+	repo := setupSQLite(t)
+
+	_, err := repo.Create(t.Context(), sqliteModel{Name: "Pen"})
+	require.NoError(t, err)
+
+	_, err = repo.Create(t.Context(), sqliteModel{Name: "Pen"})
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+}
+
+func TestSQLiteQueryTimeout(t *testing.T) {
+	repo := setupSQLite(t)
+	repo.QueryTimeout = 50 * time.Millisecond
+
+	_, err := repo.Create(t.Context(), sqliteModel{Name: "Pen"})
+	require.NoError(t, err)
+
+	// Counting a billion generated rows takes far longer than the timeout.
+	slow := "WHERE price < (WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n WHERE x < 1000000000) SELECT COUNT(*) FROM n)"
+
+	start := time.Now()
+	_, err = repo.FindAll(t.Context(), slow, map[string]interface{}{}, "", nil, pagination.Params{Limit: 1})
+
+	assert.ErrorIs(t, err, apperror.ErrTimeout)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestSQLiteQueryCanceled(t *testing.T) {
+	repo := setupSQLite(t)
+
+	_, err := repo.Create(t.Context(), sqliteModel{Name: "Pen"})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	slow := "WHERE price < (WITH RECURSIVE n(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM n WHERE x < 1000000000) SELECT COUNT(*) FROM n)"
+
+	start := time.Now()
+	_, err = repo.FindAll(ctx, slow, map[string]interface{}{}, "", nil, pagination.Params{Limit: 1})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.ErrorIs(t, err, apperror.ErrCanceled)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestSQLiteUpsert(t *testing.T) {
+	repo := setupSQLite(t)
+
+	created, err := repo.Upsert(t.Context(), "id", sqliteModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Pen", Price: 1})
+	require.NoError(t, err)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", created.ID)
+	assert.NotNil(t, created.CreatedAt)
+
+	updated, err := repo.Upsert(t.Context(), "id", sqliteModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Pencil", Price: 2})
+	require.NoError(t, err)
+	assert.Equal(t, "Pencil", updated.Name)
+	assert.Equal(t, *created.CreatedAt, *updated.CreatedAt)
+
+	byName, err := repo.Upsert(t.Context(), "name", sqliteModel{Name: "Pencil", Price: 3})
+	require.NoError(t, err)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", byName.ID)
+	assert.Equal(t, 3.0, byName.Price)
+
+	inserted, err := repo.Upsert(t.Context(), "name", sqliteModel{Name: "Eraser", Price: 4})
+	require.NoError(t, err)
+	assert.NotEmpty(t, inserted.ID)
+
+	// A new id whose name belongs to another row.
+	_, err = repo.Upsert(t.Context(), "id", sqliteModel{ID: "01JW4MH8S671QVVGD0NYY1XWAQ", Name: "Eraser"})
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+
+	_, err = repo.Upsert(t.Context(), "name", sqliteModel{Price: 1})
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+}
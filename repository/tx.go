@@ -0,0 +1,163 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrNotRegistered = errors.New("repository not registered")
+
+// executor is implemented by both *sqlx.DB and *sqlx.Tx.
+type executor interface {
+	sqlx.QueryerContext
+	sqlx.ExecerContext
+}
+
+// UnitOfWork runs functions inside database transactions and hands out
+// repositories bound to them.
+type UnitOfWork struct {
+	DB *sqlx.DB
+	// Isolation is used by RunInTx unless overridden with WithIsolation.
+	Isolation sql.IsolationLevel
+
+	mu    sync.RWMutex
+	repos map[reflect.Type]any
+}
+
+func NewUnitOfWork(db *sqlx.DB) *UnitOfWork {
+	return &UnitOfWork{DB: db, repos: map[reflect.Type]any{}}
+}
+
+// Register makes repo available to For within transactions of u.
+func Register[T any](u *UnitOfWork, repo *SqlxRepository[T]) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.repos[reflect.TypeFor[T]()] = repo
+}
+
+// Tx is a transaction started by UnitOfWork.RunInTx.
+type Tx struct {
+	*sqlx.Tx
+
+	uow        *UnitOfWork
+	savepoints int
+}
+
+// For returns the repository registered for T, bound to tx.
+func For[T any](tx *Tx) (*SqlxRepository[T], error) {
+	tx.uow.mu.RLock()
+	repo, ok := tx.uow.repos[reflect.TypeFor[T]()].(*SqlxRepository[T])
+	tx.uow.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotRegistered, reflect.TypeFor[T]())
+	}
+
+	return repo.WithTx(tx), nil
+}
+
+type TxOption func(*sql.TxOptions)
+
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(o *sql.TxOptions) {
+		o.Isolation = level
+	}
+}
+
+func ReadOnly() TxOption {
+	return func(o *sql.TxOptions) {
+		o.ReadOnly = true
+	}
+}
+
+// RunInTx commits when fn returns nil and rolls back when it returns an
+// error or panics; panics are re-raised after the rollback.
+func (u *UnitOfWork) RunInTx(ctx context.Context, fn func(tx *Tx) error, opts ...TxOption) (err error) {
+	options := &sql.TxOptions{Isolation: u.Isolation}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	sqlxTx, err := u.DB.BeginTxx(ctx, options)
+	if err != nil {
+		return err
+	}
+
+	tx := &Tx{Tx: sqlxTx, uow: u}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_ = sqlxTx.Rollback()
+			panic(recovered)
+		}
+
+		if err != nil {
+			err = errors.Join(err, ignoreDone(sqlxTx.Rollback()))
+			return
+		}
+
+		err = sqlxTx.Commit()
+	}()
+
+	return fn(tx)
+}
+
+// RunInTx runs fn inside a savepoint of tx, so an error or panic only undoes
+// the work done by fn.
+func (tx *Tx) RunInTx(ctx context.Context, fn func(tx *Tx) error) (err error) {
+	tx.savepoints++
+	name := fmt.Sprintf("sp_%d", tx.savepoints)
+
+	if _, err := tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return err
+	}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			_, _ = tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			panic(recovered)
+		}
+
+		if err != nil {
+			_, rollbackErr := tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name)
+			err = errors.Join(err, rollbackErr)
+			return
+		}
+
+		_, err = tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name)
+	}()
+
+	return fn(tx)
+}
+
+type txKey struct{}
+
+// WithinTx runs fn with a context carrying a transaction, which repositories
+// of the same database use automatically. When ctx already carries one, fn
+// runs in a savepoint of it instead.
+func (u *UnitOfWork) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*Tx); ok && tx.uow.DB == u.DB {
+		return tx.RunInTx(ctx, func(*Tx) error {
+			return fn(ctx)
+		})
+	}
+
+	return u.RunInTx(ctx, func(tx *Tx) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+}
+
+func ignoreDone(err error) error {
+	if errors.Is(err, sql.ErrTxDone) {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/pagination"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupUnitOfWork(t *testing.T) (*UnitOfWork, *SqlxRepository[sqliteModel]) {
+	repo := setupSQLite(t)
+
+	uow := NewUnitOfWork(repo.DB)
+	Register(uow, repo)
+
+	return uow, repo
+}
+
+func countRows(t *testing.T, repo *SqlxRepository[sqliteModel]) int {
+	var total int
+	require.NoError(t, repo.DB.Get(&total, `SELECT COUNT(*) FROM "product"`))
+	return total
+}
+
+func TestRunInTxCommit(t *testing.T) {
+	uow, repo := setupUnitOfWork(t)
+
+	err := uow.RunInTx(t.Context(), func(tx *Tx) error {
+		products, err := For[sqliteModel](tx)
+		if err != nil {
+			return err
+		}
+
+		if _, err := products.Create(t.Context(), sqliteModel{Name: "Pen"}); err != nil {
+			return err
+		}
+		_, err = products.Create(t.Context(), sqliteModel{Name: "Pencil"})
+		return err
+	}, WithIsolation(sql.LevelSerializable))
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countRows(t, repo))
+}
+
+func TestRunInTxRollbackOnError(t *testing.T) {
+	uow, repo := setupUnitOfWork(t)
+
+	err := uow.RunInTx(t.Context(), func(tx *Tx) error {
+		products, _ := For[sqliteModel](tx)
+
+		if _, err := products.Create(t.Context(), sqliteModel{Name: "Pen"}); err != nil {
+			return err
+		}
+		_, err := products.Create(t.Context(), sqliteModel{Name: "Pen"})
+		return err
+	})
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+	assert.Equal(t, 0, countRows(t, repo))
+}
+
+func TestRunInTxRollbackOnPanic(t *testing.T) {
+	uow, repo := setupUnitOfWork(t)
+
+	assert.PanicsWithValue(t, "boom", func() {
+		uow.RunInTx(t.Context(), func(tx *Tx) error {
+			products, _ := For[sqliteModel](tx)
+			products.Create(t.Context(), sqliteModel{Name: "Pen"})
+			panic("boom")
+		})
+	})
+
+	assert.Equal(t, 0, countRows(t, repo))
+}
+
+func TestRunInTxSavepoint(t *testing.T) {
+	uow, repo := setupUnitOfWork(t)
+	errSkip := errors.New("skip")
+
+	err := uow.RunInTx(t.Context(), func(tx *Tx) error {
+		products := repo.WithTx(tx)
+
+		if _, err := products.Create(t.Context(), sqliteModel{Name: "Pen"}); err != nil {
+			return err
+		}
+
+		err := tx.RunInTx(t.Context(), func(tx *Tx) error {
+			if _, err := products.Create(t.Context(), sqliteModel{Name: "Pencil"}); err != nil {
+				return err
+			}
+			return errSkip
+		})
+		assert.ErrorIs(t, err, errSkip)
+
+		return tx.RunInTx(t.Context(), func(tx *Tx) error {
+			_, err := products.Create(t.Context(), sqliteModel{Name: "Eraser"})
+			return err
+		})
+	})
+
+	assert.NoError(t, err)
+
+	page, err := repo.FindAll(context.Background(), "", map[string]interface{}{}, "ORDER BY name", nil, pagination.Params{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "Eraser", page.Items[0].Name)
+	assert.Equal(t, "Pen", page.Items[1].Name)
+}
+
+func TestForNotRegistered(t *testing.T) {
+	uow, _ := setupUnitOfWork(t)
+
+	err := uow.RunInTx(t.Context(), func(tx *Tx) error {
+		_, err := For[TestModel](tx)
+		return err
+	})
+
+	assert.ErrorIs(t, err, ErrNotRegistered)
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/dialect"
+	"api_boilerplate/versioning"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// insertColumns are the columns written by inserts: Fields, plus version
+// when the repository is versioned.
+func (r *SqlxRepository[T]) insertColumns() []string {
+	if !r.Versioned {
+		return r.Fields
+	}
+
+	return append(slices.Clone(r.Fields), versioning.Column)
+}
+
+// bump renders the assignment incrementing the version, if any, to append to
+// a SET list.
+func (r *SqlxRepository[T]) bump() string {
+	if !r.Versioned {
+		return ""
+	}
+
+	column := r.Dialect.Quote(versioning.Column)
+	return fmt.Sprintf(", %s = %s + 1", column, column)
+}
+
+// expected renders the condition matching the versions expected by ctx,
+// with placeholder(i) standing for the i-th of the returned values.
+func (r *SqlxRepository[T]) expected(ctx context.Context, placeholder func(i int) string) (string, []interface{}, bool) {
+	versions, ok := versioning.Expected(ctx)
+	if !r.Versioned || !ok {
+		return "", nil, false
+	}
+
+	if len(versions) == 0 {
+		// No tag of the If-Match header can match.
+		return "1 = 0", nil, true
+	}
+
+	placeholders := make([]string, len(versions))
+	values := make([]interface{}, len(versions))
+	for i, version := range versions {
+		placeholders[i] = placeholder(i)
+		values[i] = version
+	}
+
+	return fmt.Sprintf("%s IN (%s)", r.Dialect.Quote(versioning.Column), strings.Join(placeholders, ", ")), values, true
+}
+
+func positional(int) string {
+	return "?"
+}
+
+// mismatch tells apart, for a versioned write that matched no row, a
+// missing row from one modified since the client read it.
+func (r *SqlxRepository[T]) mismatch(ctx context.Context, id string, err error) error {
+	if _, _, ok := r.expected(ctx, positional); !ok || !errors.Is(err, apperror.ErrNotFound) {
+		return err
+	}
+
+	if _, findErr := r.FindByID(ctx, id, []string{"id"}); findErr != nil {
+		return err
+	}
+
+	return apperror.PreconditionFailed(err, "%s %s has been modified", r.TableName, id)
+}
+
+// checkVersion fails unless the row whose key equals value is at the
+// version expected by ctx.
+func (r *SqlxRepository[T]) checkVersion(ctx context.Context, key string, value interface{}) error {
+	condition, versions, ok := r.expected(ctx, positional)
+	if !ok {
+		return nil
+	}
+
+	query := fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s WHERE %s = ? AND %s",
+		r.Dialect.Quote(r.TableName),
+		r.Dialect.Quote(key),
+		condition,
+	)
+
+	ctx, cancel := r.withTimeout(ctx)
+	defer cancel()
+
+	var count int
+	args := append([]interface{}{value}, versions...)
+	if err := sqlx.GetContext(ctx, r.conn(ctx), &count, dialect.Rebind(r.Dialect, query), args...); err != nil {
+		return r.translateError(err, fmt.Sprint(value))
+	}
+
+	if count == 0 {
+		return apperror.PreconditionFailed(nil, "%s %v does not match If-Match", r.TableName, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"testing"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/versioning"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versionedModel struct {
+	ID        string  `db:"id" json:"id"`
+	Name      string  `db:"name" json:"name"`
+	CreatedAt *string `db:"created_at" json:"created_at"`
+	UpdatedAt *string `db:"updated_at" json:"updated_at"`
+	Version   int64   `db:"version" json:"version"`
+}
+
+func setupVersioned(t *testing.T) *SqlxRepository[versionedModel] {
+	repo := newSQLiteRepo[versionedModel](t, `CREATE TABLE "doc" (
+		"id" TEXT PRIMARY KEY,
+		"name" TEXT NOT NULL UNIQUE,
+		"created_at" TEXT,
+		"updated_at" TEXT,
+		"version" INTEGER NOT NULL DEFAULT 1
+	)`, "doc", []string{"id", "name", "created_at", "updated_at"})
+	repo.Versioned = true
+
+	return repo
+}
+
+func TestVersionedWrites(t *testing.T) {
+	repo := setupVersioned(t)
+
+	created, err := repo.Create(t.Context(), versionedModel{Name: "a", Version: 9})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created.Version)
+
+	require.NoError(t, repo.Update(t.Context(), created.ID, versionedModel{Name: "b"}))
+
+	stale := versioning.WithExpected(t.Context(), []int64{1})
+	assert.ErrorIs(t, repo.Update(stale, created.ID, versionedModel{Name: "c"}), apperror.ErrPreconditionFailed)
+	assert.ErrorIs(t, repo.Patch(stale, created.ID, map[string]interface{}{"name": "c"}), apperror.ErrPreconditionFailed)
+	assert.ErrorIs(t, repo.Delete(stale, created.ID), apperror.ErrPreconditionFailed)
+
+	none := versioning.WithExpected(t.Context(), []int64{})
+	assert.ErrorIs(t, repo.Update(none, created.ID, versionedModel{Name: "c"}), apperror.ErrPreconditionFailed)
+
+	current := versioning.WithExpected(t.Context(), []int64{1, 2})
+	require.NoError(t, repo.Patch(current, created.ID, map[string]interface{}{"name": "c"}))
+
+	found, err := repo.FindByID(t.Context(), created.ID, []string{"name"})
+	require.NoError(t, err)
+	assert.Equal(t, "c", found.Name)
+	assert.Equal(t, int64(3), found.Version)
+
+	assert.ErrorIs(t, repo.Delete(versioning.WithExpected(t.Context(), []int64{3}), "missing"), apperror.ErrNotFound)
+	require.NoError(t, repo.Delete(versioning.WithExpected(t.Context(), []int64{3}), created.ID))
+}
+
+func TestVersionedUpsert(t *testing.T) {
+	repo := setupVersioned(t)
+
+	created, err := repo.Upsert(t.Context(), "name", versionedModel{Name: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), created.Version)
+
+	_, err = repo.Upsert(versioning.WithExpected(t.Context(), []int64{5}), "name", versionedModel{Name: "a"})
+	assert.ErrorIs(t, err, apperror.ErrPreconditionFailed)
+
+	updated, err := repo.Upsert(versioning.WithExpected(t.Context(), []int64{1}), "name", versionedModel{Name: "a"})
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, updated.ID)
+	assert.Equal(t, int64(2), updated.Version)
+
+	many, err := repo.CreateMany(t.Context(), []versionedModel{{Name: "b"}, {Name: "c"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), many[1].Version)
+}
@@ -0,0 +1,65 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// Run listens on srv.Addr and serves until SIGINT or SIGTERM. See Serve.
+func Run(srv *http.Server, drainTimeout time.Duration, closers ...io.Closer) error {
+	ln, err := net.Listen("tcp", srv.Addr)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	return Serve(ctx, srv, ln, drainTimeout, closers...)
+}
+
+// Serve handles requests on ln until ctx is done. It then stops accepting
+// connections, waits up to drainTimeout for in-flight requests and finally
+// closes closers (e.g. the database pool) in order.
+func Serve(
+	ctx context.Context,
+	srv *http.Server,
+	ln net.Listener,
+	drainTimeout time.Duration,
+	closers ...io.Closer,
+) error {
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ln)
+	}()
+
+	var err error
+	select {
+	case err = <-serveErr:
+	case <-ctx.Done():
+		slog.Info("shutting down", "drain_timeout", drainTimeout)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer cancel()
+
+		err = srv.Shutdown(shutdownCtx)
+	}
+
+	if errors.Is(err, http.ErrServerClosed) {
+		err = nil
+	}
+
+	for _, closer := range closers {
+		err = errors.Join(err, closer.Close())
+	}
+
+	return err
+}
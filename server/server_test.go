@@ -0,0 +1,117 @@
+package server
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type closerFunc func() error
+
+func (f closerFunc) Close() error {
+	return f()
+}
+
+func freeAddr(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	return ln.Addr().String()
+}
+
+func TestRunDrainsInFlightRequestsOnSignal(t *testing.T) {
+	var finished, closedAfterFinish atomic.Bool
+	started := make(chan struct{})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(300 * time.Millisecond)
+		finished.Store(true)
+		io.WriteString(w, "done")
+	})
+
+	addr := freeAddr(t)
+	srv := &http.Server{Addr: addr, Handler: mux}
+	pool := closerFunc(func() error {
+		closedAfterFinish.Store(finished.Load())
+		return nil
+	})
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- Run(srv, 5*time.Second, pool)
+	}()
+
+	type response struct {
+		status int
+		body   string
+		err    error
+	}
+	slow := make(chan response, 1)
+	go func() {
+		var resp *http.Response
+		var err error
+		for i := 0; i < 50; i++ {
+			if resp, err = http.Get("http://" + addr + "/slow"); err == nil {
+				break
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+		if err != nil {
+			slow <- response{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		slow <- response{status: resp.StatusCode, body: string(body), err: err}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(2 * time.Second):
+		t.Fatal("slow request never reached the server")
+	}
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+
+	res := <-slow
+	require.NoError(t, res.err)
+	assert.Equal(t, http.StatusOK, res.status)
+	assert.Equal(t, "done", res.body)
+
+	select {
+	case err := <-runErr:
+		assert.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+
+	assert.True(t, closedAfterFinish.Load(), "pool closed before in-flight request finished")
+
+	_, err := http.Get("http://" + addr + "/slow")
+	assert.Error(t, err)
+}
+
+func TestServeReturnsWhenListenerFails(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	ln.Close()
+
+	closed := false
+	err = Serve(t.Context(), &http.Server{}, ln, time.Second, closerFunc(func() error {
+		closed = true
+		return nil
+	}))
+
+	assert.Error(t, err)
+	assert.True(t, closed)
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/validation"
+)
+
+// BulkMode selects how a bulk operation handles failing items.
+type BulkMode string
+
+const (
+	// BulkAtomic applies every item or none of them.
+	BulkAtomic BulkMode = "atomic"
+	// BulkPartial applies the items that succeed and reports the others.
+	BulkPartial BulkMode = "partial"
+)
+
+// ParseBulkMode parses the mode query parameter, defaulting to BulkAtomic.
+func ParseBulkMode(raw string) (BulkMode, error) {
+	switch mode := BulkMode(raw); mode {
+	case "":
+		return BulkAtomic, nil
+	case BulkAtomic, BulkPartial:
+		return mode, nil
+	default:
+		return "", apperror.Validation(nil, "mode must be %s or %s", BulkAtomic, BulkPartial)
+	}
+}
+
+// Transactor runs fn in a transaction carried by the context it receives,
+// nesting in a savepoint when ctx already carries one.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// BulkPatch is one item of a bulk patch: a JSON Merge Patch for the entity ID.
+type BulkPatch struct {
+	ID    string          `json:"id"`
+	Patch json.RawMessage `json:"patch"`
+}
+
+// BulkResult is the outcome of one item of a bulk operation.
+type BulkResult[T any] struct {
+	Index int
+	ID    string
+	Item  *T
+	Err   error
+}
+
+func (s *GenericServiceImpl[T]) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if s.Tx == nil {
+		return fn(ctx)
+	}
+
+	return s.Tx.WithinTx(ctx, fn)
+}
+
+// partial runs fn for every item in its own savepoint of one transaction,
+// so a failing item does not undo the others.
+func (s *GenericServiceImpl[T]) partial(
+	ctx context.Context,
+	n int,
+	fn func(ctx context.Context, i int) (BulkResult[T], error),
+) ([]BulkResult[T], error) {
+	results := make([]BulkResult[T], n)
+
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		for i := 0; i < n; i++ {
+			var result BulkResult[T]
+			err := s.withinTx(ctx, func(ctx context.Context) error {
+				var err error
+				result, err = fn(ctx, i)
+				return err
+			})
+
+			result.Index = i
+			result.Err = err
+			results[i] = result
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// CreateMany validates and runs the create hooks for every item, then inserts
+// them in batches within one transaction.
+func (s *GenericServiceImpl[T]) CreateMany(ctx context.Context, items []T, mode BulkMode) ([]BulkResult[T], error) {
+	if mode == BulkPartial {
+		return s.partial(ctx, len(items), func(ctx context.Context, i int) (BulkResult[T], error) {
+			created, err := s.Create(ctx, items[i])
+			return BulkResult[T]{ID: idOf(created), Item: &created}, err
+		})
+	}
+
+	for i := range items {
+		if err := validation.Validate(items[i]); err != nil {
+			return nil, atItem(i, err)
+		}
+
+		if err := s.runHooks(ctx, beforeCreate, "", &items[i]); err != nil {
+			return nil, atItem(i, err)
+		}
+	}
+
+	var results []BulkResult[T]
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		created, err := s.Repo.CreateMany(ctx, items)
+		if err != nil {
+			return err
+		}
+
+		results = make([]BulkResult[T], len(created))
+		for i := range created {
+			id := idOf(created[i])
+			if err := s.runHooks(ctx, afterCreate, id, &created[i]); err != nil {
+				return atItem(i, err)
+			}
+
+			results[i] = BulkResult[T]{Index: i, ID: id, Item: &created[i]}
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// PatchMany applies a JSON Merge Patch to each entity.
+func (s *GenericServiceImpl[T]) PatchMany(ctx context.Context, patches []BulkPatch, mode BulkMode) ([]BulkResult[T], error) {
+	patch := func(ctx context.Context, i int) (BulkResult[T], error) {
+		patched, err := s.Patch(ctx, patches[i].ID, MergePatch, patches[i].Patch)
+		return BulkResult[T]{Index: i, ID: patches[i].ID, Item: &patched}, err
+	}
+
+	if mode == BulkPartial {
+		return s.partial(ctx, len(patches), patch)
+	}
+
+	results := make([]BulkResult[T], len(patches))
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		for i := range patches {
+			result, err := patch(ctx, i)
+			if err != nil {
+				return atItem(i, err)
+			}
+
+			results[i] = result
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// DeleteMany deletes the entities with the given ids, which must be
+// distinct. In atomic mode a missing id fails the whole operation.
+func (s *GenericServiceImpl[T]) DeleteMany(ctx context.Context, ids []string, mode BulkMode) ([]BulkResult[T], error) {
+	seen := make(map[string]bool, len(ids))
+	for i, id := range ids {
+		if seen[id] {
+			return nil, apperror.Validation(nil, "item %d: id %s is repeated", i, id)
+		}
+		seen[id] = true
+	}
+
+	remove := func(ctx context.Context, i int) (BulkResult[T], error) {
+		return BulkResult[T]{Index: i, ID: ids[i]}, s.Delete(ctx, ids[i])
+	}
+
+	if mode == BulkPartial {
+		return s.partial(ctx, len(ids), remove)
+	}
+
+	results := make([]BulkResult[T], len(ids))
+	for i, id := range ids {
+		results[i] = BulkResult[T]{Index: i, ID: id}
+	}
+
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		if !s.hasDeleteHooks() {
+			deleted, err := s.Repo.DeleteMany(ctx, ids)
+			if err != nil {
+				return err
+			}
+
+			if deleted != int64(len(ids)) {
+				return apperror.NotFound(nil, "%d of %d ids were not found", int64(len(ids))-deleted, len(ids))
+			}
+
+			return nil
+		}
+
+		for i := range ids {
+			if _, err := remove(ctx, i); err != nil {
+				return atItem(i, err)
+			}
+		}
+
+		return nil
+	})
+
+	return results, err
+}
+
+// atItem prefixes err with the index of the bulk item that caused it,
+// keeping its apperror kind.
+func atItem(index int, err error) error {
+	var appErr *apperror.Error
+	if errors.As(err, &appErr) {
+		return apperror.New(appErr.Kind, appErr.Err, "item %d: %s", index, appErr.Message).WithFields(appErr.Fields...)
+	}
+
+	return fmt.Errorf("item %d: %w", index, err)
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"api_boilerplate/apperror"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTransactor counts transactions without a database.
+type mockTransactor struct {
+	calls int
+}
+
+func (m *mockTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.calls++
+	return fn(ctx)
+}
+
+func TestParseBulkMode(t *testing.T) {
+	mode, err := ParseBulkMode("")
+	assert.NoError(t, err)
+	assert.Equal(t, BulkAtomic, mode)
+
+	mode, err = ParseBulkMode("partial")
+	assert.NoError(t, err)
+	assert.Equal(t, BulkPartial, mode)
+
+	_, err = ParseBulkMode("best-effort")
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+}
+
+func TestBulk_CreateManyAtomic(t *testing.T) {
+	tx := &mockTransactor{}
+	repo := &MockRepository[TestModel]{
+		CreateManyFn: func(items []TestModel) ([]TestModel, error) {
+			for i := range items {
+				items[i].ID = fmt.Sprint("id-", i)
+			}
+			return items, nil
+		},
+	}
+	service := &GenericServiceImpl[TestModel]{Repo: repo, Tx: tx}
+
+	results, err := service.CreateMany(t.Context(), []TestModel{{Name: "a"}, {Name: "b"}}, BulkAtomic)
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "id-1", results[1].ID)
+	assert.Equal(t, "b", results[1].Item.Name)
+	assert.Equal(t, 1, tx.calls)
+}
+
+func TestBulk_CreateManyAtomicInvalid(t *testing.T) {
+	repo := &MockRepository[TestModel]{
+		CreateManyFn: func(items []TestModel) ([]TestModel, error) {
+			t.Fatal("invalid items must not reach the repository")
+			return nil, nil
+		},
+	}
+	service := &GenericServiceImpl[TestModel]{Repo: repo, Tx: &mockTransactor{}}
+
+	_, err := service.CreateMany(t.Context(), []TestModel{{Name: "a"}, {Age: -1}}, BulkAtomic)
+
+	var appErr *apperror.Error
+	require.ErrorAs(t, err, &appErr)
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	assert.Contains(t, appErr.Message, "item 1")
+	assert.Len(t, appErr.Fields, 2)
+}
+
+func TestBulk_CreateManyPartial(t *testing.T) {
+	tx := &mockTransactor{}
+	repo := &MockRepository[TestModel]{
+		CreateFn: func(item TestModel) (TestModel, error) {
+			item.ID = "id-" + item.Name
+			return item, nil
+		},
+	}
+	service := &GenericServiceImpl[TestModel]{Repo: repo, Tx: tx}
+
+	results, err := service.CreateMany(t.Context(), []TestModel{{Name: "a"}, {Age: -1}, {Name: "c"}}, BulkPartial)
+
+	require.NoError(t, err)
+	require.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, "id-a", results[0].ID)
+	assert.ErrorIs(t, results[1].Err, apperror.ErrValidation)
+	assert.Equal(t, 1, results[1].Index)
+	assert.Equal(t, "id-c", results[2].ID)
+	// One transaction plus a savepoint per item.
+	assert.Equal(t, 4, tx.calls)
+}
+
+func TestBulk_PatchManyAtomic(t *testing.T) {
+	repo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			if id == "missing" {
+				return TestModel{}, apperror.NotFound(nil, "not found")
+			}
+			return TestModel{ID: id, Name: "Name"}, nil
+		},
+		PatchFn: func(id string, changes map[string]interface{}) error {
+			return nil
+		},
+	}
+	service := &GenericServiceImpl[TestModel]{Repo: repo, Tx: &mockTransactor{}}
+
+	patches := []BulkPatch{
+		{ID: "a", Patch: []byte(`{"age":1}`)},
+		{ID: "missing", Patch: []byte(`{"age":2}`)},
+	}
+	_, err := service.PatchMany(t.Context(), patches, BulkAtomic)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+	assert.ErrorContains(t, err, "item 1")
+}
+
+func TestBulk_DeleteManyAtomicMissing(t *testing.T) {
+	repo := &MockRepository[TestModel]{
+		DeleteManyFn: func(ids []string) (int64, error) {
+			return int64(len(ids) - 1), nil
+		},
+	}
+	service := &GenericServiceImpl[TestModel]{Repo: repo, Tx: &mockTransactor{}}
+
+	_, err := service.DeleteMany(t.Context(), []string{"a", "b"}, BulkAtomic)
+
+	assert.ErrorIs(t, err, apperror.ErrNotFound)
+}
+
+func TestBulk_DeleteManyRepeatedIDs(t *testing.T) {
+	service := &GenericServiceImpl[TestModel]{Repo: &MockRepository[TestModel]{}, Tx: &mockTransactor{}}
+
+	for _, mode := range []BulkMode{BulkAtomic, BulkPartial} {
+		_, err := service.DeleteMany(t.Context(), []string{"a", "b", "a"}, mode)
+
+		assert.ErrorIs(t, err, apperror.ErrValidation)
+		assert.ErrorContains(t, err, "item 2")
+	}
+}
+
+func TestBulk_DeleteManyPartial(t *testing.T) {
+	repo := &MockRepository[TestModel]{
+		DeleteFn: func(id string) error {
+			if id == "b" {
+				return apperror.NotFound(nil, "not found")
+			}
+			return nil
+		},
+	}
+	service := NewGenericService[TestModel](repo)
+
+	results, err := service.DeleteMany(t.Context(), []string{"a", "b"}, BulkPartial)
+
+	require.NoError(t, err)
+	assert.NoError(t, results[0].Err)
+	assert.ErrorIs(t, results[1].Err, apperror.ErrNotFound)
+	assert.Equal(t, "b", results[1].ID)
+}
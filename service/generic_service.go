@@ -1,59 +1,442 @@
 package service
 
 import (
-	"github.com/gin-gonic/gin"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/pagination"
+	"api_boilerplate/softdelete"
+	"api_boilerplate/validation"
+	"api_boilerplate/versioning"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/oklog/ulid/v2"
+)
+
+type PatchType string
+
+const (
+	MergePatch PatchType = "application/merge-patch+json"
+	JSONPatch  PatchType = "application/json-patch+json"
+)
+
+var (
+	ErrInvalidPatch     = errors.New("invalid patch document")
+	ErrUnsupportedPatch = errors.New("unsupported patch type")
 )
 
 type GenericRepository[T any] interface {
-	FindAll(query string, filtersQUery map[string]interface{}) ([]T, error)
-	FindByID(id string) (T, error)
-	Create(item T) error
-	Update(id string, item T) error
-	Delete(id string) error
+	FindAll(
+		ctx context.Context,
+		query string,
+		filtersQUery map[string]interface{},
+		sort string,
+		fields []string,
+		page pagination.Params,
+	) (pagination.Page[T], error)
+	FindByID(ctx context.Context, id string, fields []string) (T, error)
+	FindBy(ctx context.Context, key string, value string, fields []string) (T, error)
+	Create(ctx context.Context, item T) (T, error)
+	Update(ctx context.Context, id string, item T) error
+	Upsert(ctx context.Context, key string, item T) (T, error)
+	Patch(ctx context.Context, id string, changes map[string]interface{}) error
+	Delete(ctx context.Context, id string) error
+	CreateMany(ctx context.Context, items []T) ([]T, error)
+	DeleteMany(ctx context.Context, ids []string) (int64, error)
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
 
 type GenericService[T any] interface {
-	GetAll(query string, filters map[string]interface{}) ([]T, error)
-	GetByID(id string) (T, error)
-	Create(item T) error
-	Update(id string, ctx *gin.Context) error
-	Delete(id string) error
+	GetAll(
+		ctx context.Context,
+		query string,
+		filters map[string]interface{},
+		sort string,
+		fields []string,
+		page pagination.Params,
+	) (pagination.Page[T], error)
+	GetByID(ctx context.Context, id string, fields []string) (T, error)
+	Create(ctx context.Context, item T) (T, error)
+	Update(ctx context.Context, id string, changes map[string]interface{}) (T, error)
+	Upsert(ctx context.Context, key string, value string, item T) (T, bool, error)
+	Patch(ctx context.Context, id string, patchType PatchType, document []byte) (T, error)
+	Delete(ctx context.Context, id string) error
+	CreateMany(ctx context.Context, items []T, mode BulkMode) ([]BulkResult[T], error)
+	PatchMany(ctx context.Context, patches []BulkPatch, mode BulkMode) ([]BulkResult[T], error)
+	DeleteMany(ctx context.Context, ids []string, mode BulkMode) ([]BulkResult[T], error)
+	Restore(ctx context.Context, id string) error
+	Purge(ctx context.Context, before time.Time) (int64, error)
 }
 
 type GenericServiceImpl[T any] struct {
-	Repo GenericRepository[T]
+	Repo  GenericRepository[T]
+	Hooks []Hooks[T]
+	// Tx makes bulk operations transactional; without it they run on the
+	// repository as is.
+	Tx Transactor
 }
 
-func NewGenericService[T any](repo GenericRepository[T]) GenericService[T] {
-	return &GenericServiceImpl[T]{Repo: repo}
+func NewGenericService[T any](repo GenericRepository[T], hooks ...Hooks[T]) GenericService[T] {
+	return &GenericServiceImpl[T]{Repo: repo, Hooks: hooks}
 }
 
-func (s *GenericServiceImpl[T]) GetAll(query string, filters map[string]interface{}) ([]T, error) {
-	return s.Repo.FindAll(query, filters)
+func (s *GenericServiceImpl[T]) GetAll(
+	ctx context.Context,
+	query string,
+	filters map[string]interface{},
+	sort string,
+	fields []string,
+	page pagination.Params,
+) (pagination.Page[T], error) {
+	return s.Repo.FindAll(ctx, query, filters, sort, fields, page)
 }
 
-func (s *GenericServiceImpl[T]) GetByID(id string) (T, error) {
-	return s.Repo.FindByID(id)
+func (s *GenericServiceImpl[T]) GetByID(ctx context.Context, id string, fields []string) (T, error) {
+	return s.Repo.FindByID(ctx, id, fields)
 }
 
-func (s *GenericServiceImpl[T]) Create(item T) error {
-	return s.Repo.Create(item)
+func (s *GenericServiceImpl[T]) Create(ctx context.Context, item T) (T, error) {
+	if err := validation.Validate(item); err != nil {
+		return item, err
+	}
+
+	if err := s.runHooks(ctx, beforeCreate, "", &item); err != nil {
+		return item, err
+	}
+
+	created, err := s.Repo.Create(ctx, item)
+	if err != nil {
+		return created, err
+	}
+
+	err = s.runHooks(ctx, afterCreate, idOf(created), &created)
+	return created, err
 }
 
-func (s *GenericServiceImpl[T]) Update(id string, ctx *gin.Context) error {
-	item, err := s.Repo.FindByID(id)
+// Update sets the fields in changes, keyed by JSON name, on the stored
+// entity, so fields it omits keep their values, and saves the result,
+// returning the stored entity.
+func (s *GenericServiceImpl[T]) Update(ctx context.Context, id string, changes map[string]interface{}) (T, error) {
+	item, err := s.Repo.FindByID(ctx, id, nil)
+	if err != nil {
+		return item, err
+	}
+
+	if item, err = withFields(item, changes); err != nil {
+		return item, err
+	}
+
+	if err := validation.Validate(item); err != nil {
+		return item, err
+	}
+
+	if err := s.runHooks(ctx, beforeUpdate, id, &item); err != nil {
+		return item, err
+	}
+
+	if err := s.Repo.Update(ctx, id, item); err != nil {
+		return item, err
+	}
+
+	// Read back the version and timestamps set by the database.
+	if item, err = s.Repo.FindByID(ctx, id, nil); err != nil {
+		return item, err
+	}
+
+	err = s.runHooks(ctx, afterUpdate, id, &item)
+	return item, err
+}
+
+// Upsert replaces the entity whose key column equals value, creating it when
+// there is none, and reports whether it was created. key is "id" or a column
+// with a unique index; entities created by another key get a generated id.
+func (s *GenericServiceImpl[T]) Upsert(ctx context.Context, key string, value string, item T) (T, bool, error) {
+	// Cursor pagination orders and parses ids as ULIDs.
+	if key == "id" {
+		if _, err := ulid.ParseStrict(value); err != nil {
+			return item, false, apperror.Validation(err, "id must be a ULID")
+		}
+	}
+
+	item, err := withField(item, key, value)
+	if err != nil {
+		return item, false, err
+	}
+
+	if err := validation.Validate(item); err != nil {
+		return item, false, err
+	}
+
+	var created bool
+	err = s.withinTx(ctx, func(ctx context.Context) error {
+		// A soft-deleted row is restored by the upsert, not created.
+		existing, err := s.Repo.FindBy(softdelete.WithScope(ctx, softdelete.WithDeleted), key, value, []string{"id"})
+		switch {
+		case errors.Is(err, apperror.ErrNotFound):
+			created = true
+		case err != nil:
+			return err
+		}
+
+		if key != "id" {
+			if item, err = withField(item, "id", idOf(existing)); err != nil {
+				return err
+			}
+		}
+
+		before, after := beforeUpdate, afterUpdate
+		if created {
+			before, after = beforeCreate, afterCreate
+		}
+
+		if err := s.runHooks(ctx, before, idOf(item), &item); err != nil {
+			return err
+		}
+
+		if item, err = s.Repo.Upsert(ctx, key, item); err != nil {
+			return err
+		}
+
+		return s.runHooks(ctx, after, idOf(item), &item)
+	})
+
+	return item, created, err
+}
+
+// withField sets the JSON field key of item.
+func withField[T any](item T, key string, value string) (T, error) {
+	return withFields(item, map[string]interface{}{key: value})
+}
+
+// withFields sets the JSON fields of item named by the keys of values.
+func withFields[T any](item T, values map[string]interface{}) (T, error) {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return item, err
+	}
+
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return item, err
+	}
+
+	for key, value := range values {
+		fields[key] = value
+	}
+	if data, err = json.Marshal(fields); err != nil {
+		return item, err
+	}
+
+	var updated T
+	if err := json.Unmarshal(data, &updated); err != nil {
+		return item, apperror.Validation(err, "invalid %s", fieldOf(err))
+	}
+
+	return updated, nil
+}
+
+// fieldOf names the field of a JSON decoding error, if known.
+func fieldOf(err error) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return typeErr.Field
+	}
+
+	return "fields"
+}
+
+// Patch applies a JSON Merge Patch (RFC 7386) or JSON Patch (RFC 6902)
+// document to the stored entity and persists only the fields it changed.
+func (s *GenericServiceImpl[T]) Patch(ctx context.Context, id string, patchType PatchType, document []byte) (T, error) {
+	item, err := s.Repo.FindByID(ctx, id, nil)
+	if err != nil {
+		return item, err
+	}
+
+	// Checked here too so a patch without changes honors If-Match.
+	if err := checkVersion(ctx, id, item); err != nil {
+		return item, err
+	}
+
+	original, err := json.Marshal(item)
+	if err != nil {
+		return item, err
+	}
 
+	patched, err := applyPatch(patchType, original, document)
+	if err != nil {
+		return item, err
+	}
+
+	if changes, err := diff(original, patched); err != nil || len(changes) == 0 {
+		return item, err
+	}
+
+	var updated T
+	if err := json.Unmarshal(patched, &updated); err != nil {
+		return item, apperror.Validation(ErrInvalidPatch, "invalid patch document: %v", err)
+	}
+
+	if err := validation.Validate(updated); err != nil {
+		return item, err
+	}
+
+	if err := s.runHooks(ctx, beforeUpdate, id, &updated); err != nil {
+		return item, err
+	}
+
+	// Diff again so changes made by hooks are persisted too.
+	if patched, err = json.Marshal(updated); err != nil {
+		return item, err
+	}
+
+	changes, err := diff(original, patched)
+	if err != nil {
+		return item, err
+	}
+
+	if err := s.Repo.Patch(ctx, id, changes); err != nil {
+		return item, err
+	}
+
+	item, err = s.Repo.FindByID(ctx, id, nil)
+	if err != nil {
+		return item, err
+	}
+
+	err = s.runHooks(ctx, afterUpdate, id, &item)
+	return item, err
+}
+
+func applyPatch(patchType PatchType, original []byte, document []byte) ([]byte, error) {
+	var patched []byte
+	var err error
+
+	switch patchType {
+	case MergePatch:
+		patched, err = jsonpatch.MergePatch(original, document)
+	case JSONPatch:
+		var patch jsonpatch.Patch
+		patch, err = jsonpatch.DecodePatch(document)
+		if err == nil {
+			patched, err = patch.Apply(original)
+		}
+	default:
+		return nil, apperror.Validation(ErrUnsupportedPatch, "unsupported patch type %q", patchType)
+	}
+
+	if err != nil {
+		return nil, apperror.Validation(ErrInvalidPatch, "invalid patch document: %v", err)
+	}
+
+	return patched, nil
+}
+
+// diff returns the top-level keys whose values differ between two JSON objects.
+// Keys removed by the patch are reported as nil.
+func diff(original []byte, patched []byte) (map[string]interface{}, error) {
+	var before, after map[string]interface{}
+
+	if err := json.Unmarshal(original, &before); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(patched, &after); err != nil {
+		return nil, apperror.Validation(ErrInvalidPatch, "invalid patch document: %v", err)
+	}
+
+	changes := map[string]interface{}{}
+	for key, value := range after {
+		if !reflect.DeepEqual(before[key], value) {
+			changes[key] = value
+		}
+	}
+	for key := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = nil
+		}
+	}
+
+	return changes, nil
+}
+
+func (s *GenericServiceImpl[T]) Delete(ctx context.Context, id string) error {
+	if !s.hasDeleteHooks() {
+		return s.Repo.Delete(ctx, id)
+	}
+
+	item, err := s.Repo.FindByID(ctx, id, nil)
 	if err != nil {
 		return err
 	}
 
-	if err := ctx.ShouldBindJSON(&item); err != nil {
+	if err := s.runHooks(ctx, beforeDelete, id, &item); err != nil {
+		return err
+	}
+
+	if err := s.Repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return s.runHooks(ctx, afterDelete, id, &item)
+}
+
+// Restore undoes the soft delete of an entity.
+func (s *GenericServiceImpl[T]) Restore(ctx context.Context, id string) error {
+	return s.Repo.Restore(ctx, id)
+}
+
+// Purge permanently removes entities soft-deleted before the given time.
+func (s *GenericServiceImpl[T]) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return s.Repo.Purge(ctx, before)
+}
+
+// checkVersion fails when ctx expects versions other than the one in the
+// "version" JSON field of item. Items without that field always pass.
+func checkVersion[T any](ctx context.Context, id string, item T) error {
+	expected, ok := versioning.Expected(ctx)
+	if !ok {
+		return nil
+	}
+
+	data, err := json.Marshal(item)
+	if err != nil {
 		return err
 	}
 
-	return s.Repo.Update(id, item)
+	var fields struct {
+		Version *int64 `json:"version"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil || fields.Version == nil {
+		return nil
+	}
+
+	if !slices.Contains(expected, *fields.Version) {
+		return apperror.PreconditionFailed(nil, "%s has been modified", id)
+	}
+
+	return nil
 }
 
-func (s *GenericServiceImpl[T]) Delete(id string) error {
-	return s.Repo.Delete(id)
+// idOf reads the "id" JSON field of item.
+func idOf[T any](item T) string {
+	data, err := json.Marshal(item)
+	if err != nil {
+		return ""
+	}
+
+	var fields struct {
+		ID interface{} `json:"id"`
+	}
+	if err := json.Unmarshal(data, &fields); err != nil || fields.ID == nil {
+		return ""
+	}
+
+	return fmt.Sprint(fields.ID)
 }
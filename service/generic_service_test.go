@@ -1,40 +1,86 @@
 package service
 
 import (
-	"bytes"
-	"net/http"
-	"net/http/httptest"
+	"context"
 	"testing"
+	"time"
+
+	"api_boilerplate/apperror"
+	"api_boilerplate/pagination"
+	"api_boilerplate/softdelete"
+	"api_boilerplate/versioning"
 
-	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
 
 type MockRepository[T any] struct {
-	FindAllFn  func() ([]T, error)
-	FindByIDFn func(string) (T, error)
-	CreateFn   func(T) error
+	FindAllFn  func(string, pagination.Params) (pagination.Page[T], error)
+	FindByIDFn func(string, []string) (T, error)
+	CreateFn   func(T) (T, error)
 	UpdateFn   func(string, T) error
+	PatchFn    func(string, map[string]interface{}) error
 	DeleteFn   func(string) error
+
+	FindByFn     func(string, string, []string) (T, error)
+	UpsertFn     func(string, T) (T, error)
+	CreateManyFn func([]T) ([]T, error)
+	DeleteManyFn func([]string) (int64, error)
+	RestoreFn    func(string) error
+	PurgeFn      func(time.Time) (int64, error)
 }
 
-func (m *MockRepository[T]) FindAll(query string, filters map[string]interface{}) ([]T, error) {
-	return m.FindAllFn()
+func (m *MockRepository[T]) FindAll(
+	ctx context.Context,
+	query string,
+	filters map[string]interface{},
+	sort string,
+	fields []string,
+	page pagination.Params,
+) (pagination.Page[T], error) {
+	return m.FindAllFn(sort, page)
+}
+func (m *MockRepository[T]) FindByID(ctx context.Context, id string, fields []string) (T, error) {
+	return m.FindByIDFn(id, fields)
+}
+func (m *MockRepository[T]) Create(ctx context.Context, item T) (T, error) { return m.CreateFn(item) }
+func (m *MockRepository[T]) Update(ctx context.Context, id string, item T) error {
+	return m.UpdateFn(id, item)
+}
+func (m *MockRepository[T]) Delete(ctx context.Context, id string) error { return m.DeleteFn(id) }
+func (m *MockRepository[T]) Patch(ctx context.Context, id string, changes map[string]interface{}) error {
+	return m.PatchFn(id, changes)
+}
+func (m *MockRepository[T]) FindBy(ctx context.Context, key string, value string, fields []string) (T, error) {
+	return m.FindByFn(key, value, fields)
+}
+func (m *MockRepository[T]) Upsert(ctx context.Context, key string, item T) (T, error) {
+	return m.UpsertFn(key, item)
+}
+func (m *MockRepository[T]) CreateMany(ctx context.Context, items []T) ([]T, error) {
+	return m.CreateManyFn(items)
+}
+func (m *MockRepository[T]) DeleteMany(ctx context.Context, ids []string) (int64, error) {
+	return m.DeleteManyFn(ids)
+}
+func (m *MockRepository[T]) Restore(ctx context.Context, id string) error { return m.RestoreFn(id) }
+func (m *MockRepository[T]) Purge(ctx context.Context, before time.Time) (int64, error) {
+	return m.PurgeFn(before)
 }
-func (m *MockRepository[T]) FindByID(id string) (T, error)  { return m.FindByIDFn(id) }
-func (m *MockRepository[T]) Create(item T) error            { return m.CreateFn(item) }
-func (m *MockRepository[T]) Update(id string, item T) error { return m.UpdateFn(id, item) }
-func (m *MockRepository[T]) Delete(id string) error         { return m.DeleteFn(id) }
 
 type TestModel struct {
-	ID   string
-	Name string
+	ID   string `json:"id"`
+	Name string `json:"name" validate:"required"`
+	Age  int    `json:"age" validate:"gte=0"`
 }
 
 func TestGenericService_GetAll(t *testing.T) {
 	mockRepo := &MockRepository[TestModel]{
-		FindAllFn: func() ([]TestModel, error) {
-			return []TestModel{{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Test"}}, nil
+		FindAllFn: func(sort string, page pagination.Params) (pagination.Page[TestModel], error) {
+			return pagination.Page[TestModel]{
+				Items: []TestModel{{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Test"}},
+				Total: 1,
+				Limit: page.Limit,
+			}, nil
 		},
 	}
 
@@ -43,21 +89,22 @@ func TestGenericService_GetAll(t *testing.T) {
 	query := "WHERE id = :id"
 
 	service := NewGenericService[TestModel](mockRepo)
-	result, err := service.GetAll(query, filters)
+	result, err := service.GetAll(t.Context(), query, filters, "", nil, pagination.Params{Limit: pagination.DefaultLimit})
 
 	assert.NoError(t, err)
-	assert.Len(t, result, 1)
-	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", result[0].ID)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", result.Items[0].ID)
+	assert.Equal(t, pagination.DefaultLimit, result.Limit)
 }
 
 func TestGenericService_GetByID(t *testing.T) {
 	mockRepo := &MockRepository[TestModel]{
-		FindByIDFn: func(id string) (TestModel, error) {
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
 			return TestModel{ID: id, Name: "Test"}, nil
 		},
 	}
 	service := NewGenericService[TestModel](mockRepo)
-	result, err := service.GetByID("01JW4MH8S671QVVGD0NYY1XWAP")
+	result, err := service.GetByID(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", nil)
 
 	assert.NoError(t, err)
 	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", result.ID)
@@ -66,16 +113,17 @@ func TestGenericService_GetByID(t *testing.T) {
 func TestGenericService_Create(t *testing.T) {
 	called := false
 	mockRepo := &MockRepository[TestModel]{
-		CreateFn: func(item TestModel) error {
+		CreateFn: func(item TestModel) (TestModel, error) {
 			called = true
-			return nil
+			return item, nil
 		},
 	}
 	service := NewGenericService[TestModel](mockRepo)
-	err := service.Create(TestModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "New"})
+	created, err := service.Create(t.Context(), TestModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "New"})
 
 	assert.NoError(t, err)
 	assert.True(t, called)
+	assert.Equal(t, "New", created.Name)
 }
 
 func TestGenericService_Delete(t *testing.T) {
@@ -87,36 +135,253 @@ func TestGenericService_Delete(t *testing.T) {
 		},
 	}
 	service := NewGenericService[TestModel](mockRepo)
-	err := service.Delete("01JW4MH8S671QVVGD0NYY1XWAP")
+	err := service.Delete(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP")
 
 	assert.NoError(t, err)
 	assert.True(t, called)
 }
 
 func TestGenericService_Update(t *testing.T) {
-	mockModel := TestModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Old Name"}
 	mockRepo := &MockRepository[TestModel]{
-		FindByIDFn: func(id string) (TestModel, error) {
-			return mockModel, nil
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "Old Name", Age: 30}, nil
 		},
 		UpdateFn: func(id string, updated TestModel) error {
 			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", id)
 			assert.Equal(t, "New Name", updated.Name)
+			assert.Equal(t, 30, updated.Age)
+			return nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	_, err := service.Update(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", map[string]interface{}{"name": "New Name"})
+
+	assert.NoError(t, err)
+}
+
+func TestGenericService_UpdateInvalid(t *testing.T) {
+	service := NewGenericService[TestModel](&MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "Old Name"}, nil
+		},
+	})
+
+	for _, changes := range []map[string]interface{}{{"name": ""}, {"age": "thirty"}} {
+		_, err := service.Update(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", changes)
+
+		assert.ErrorIs(t, err, apperror.ErrValidation, changes)
+	}
+}
+
+func TestGenericService_PatchMerge(t *testing.T) {
+	stored := TestModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "Old Name", Age: 30}
+	mockRepo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return stored, nil
+		},
+		PatchFn: func(id string, changes map[string]interface{}) error {
+			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", id)
+			assert.Equal(t, map[string]interface{}{"name": "New Name"}, changes)
+			stored.Name = "New Name"
+			return nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	item, err := service.Patch(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", MergePatch, []byte(`{"name":"New Name","age":30}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "New Name", item.Name)
+	assert.Equal(t, 30, item.Age)
+}
+
+func TestGenericService_PatchJSONPatch(t *testing.T) {
+	mockRepo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "Old Name", Age: 30}, nil
+		},
+		PatchFn: func(id string, changes map[string]interface{}) error {
+			assert.Equal(t, map[string]interface{}{"age": float64(31)}, changes)
+			return nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	document := `[{"op":"test","path":"/name","value":"Old Name"},{"op":"replace","path":"/age","value":31}]`
+	_, err := service.Patch(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", JSONPatch, []byte(document))
+
+	assert.NoError(t, err)
+}
+
+func TestGenericService_PatchNoChanges(t *testing.T) {
+	mockRepo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "Name"}, nil
+		},
+		PatchFn: func(id string, changes map[string]interface{}) error {
+			t.Fatal("repository should not be called without changes")
+			return nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	_, err := service.Patch(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", MergePatch, []byte(`{"name":"Name"}`))
+
+	assert.NoError(t, err)
+}
+
+func TestGenericService_PatchInvalid(t *testing.T) {
+	mockRepo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "Name"}, nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	documents := map[PatchType]string{
+		MergePatch: `not json`,
+		JSONPatch:  `[{"op":"test","path":"/name","value":"Other"}]`,
+	}
+
+	for patchType, document := range documents {
+		_, err := service.Patch(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", patchType, []byte(document))
+		assert.ErrorIs(t, err, ErrInvalidPatch, document)
+	}
+
+	_, err := service.Patch(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", "application/json", []byte(`{}`))
+	assert.ErrorIs(t, err, ErrUnsupportedPatch)
+}
+
+func TestGenericService_CreateInvalid(t *testing.T) {
+	mockRepo := &MockRepository[TestModel]{
+		CreateFn: func(item TestModel) (TestModel, error) {
+			t.Fatal("invalid items must not reach the repository")
+			return item, nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	_, err := service.Create(t.Context(), TestModel{Age: -1})
+
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+	assert.Len(t, appErr.Fields, 2)
+}
+
+func TestGenericService_PatchInvalidEntity(t *testing.T) {
+	mockRepo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "Name"}, nil
+		},
+		PatchFn: func(id string, changes map[string]interface{}) error {
+			t.Fatal("invalid patches must not reach the repository")
 			return nil
 		},
 	}
 	service := NewGenericService[TestModel](mockRepo)
 
-	// Criando contexto simulado com JSON
-	gin.SetMode(gin.TestMode)
-	w := httptest.NewRecorder()
-	ctx, _ := gin.CreateTestContext(w)
+	_, err := service.Patch(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", MergePatch, []byte(`{"name":null}`))
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+}
+
+func TestGenericService_UpsertCreatesByID(t *testing.T) {
+	mockRepo := &MockRepository[TestModel]{
+		FindByFn: func(key string, value string, fields []string) (TestModel, error) {
+			assert.Equal(t, "id", key)
+			return TestModel{}, apperror.NotFound(nil, "not found")
+		},
+		UpsertFn: func(key string, item TestModel) (TestModel, error) {
+			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", item.ID)
+			return item, nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	item, created, err := service.Upsert(t.Context(), "id", "01JW4MH8S671QVVGD0NYY1XWAP", TestModel{Name: "New"})
 
-	body := bytes.NewBufferString(`{"name":"New Name"}`)
-	ctx.Request, _ = http.NewRequest(http.MethodPut, "/test/1", body)
-	ctx.Request.Header.Set("Content-Type", "application/json")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", item.ID)
+}
+
+func TestGenericService_UpsertReplacesByNaturalKey(t *testing.T) {
+	mockRepo := &MockRepository[TestModel]{
+		FindByFn: func(key string, value string, fields []string) (TestModel, error) {
+			assert.Equal(t, "name", key)
+			assert.Equal(t, "john", value)
+			return TestModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP"}, nil
+		},
+		UpsertFn: func(key string, item TestModel) (TestModel, error) {
+			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", item.ID)
+			assert.Equal(t, "john", item.Name)
+			return item, nil
+		},
+	}
+	service := NewGenericService[TestModel](mockRepo)
+
+	item, created, err := service.Upsert(t.Context(), "name", "john", TestModel{ID: "ignored", Age: 40})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, 40, item.Age)
+}
+
+// scopedRepository records the soft-delete scope of FindBy calls.
+type scopedRepository struct {
+	*MockRepository[TestModel]
+	scope softdelete.Scope
+}
+
+func (r *scopedRepository) FindBy(ctx context.Context, key string, value string, fields []string) (TestModel, error) {
+	r.scope = softdelete.FromContext(ctx)
+	return TestModel{ID: value}, nil
+}
+
+func TestGenericService_UpsertRestoresSoftDeleted(t *testing.T) {
+	repo := &scopedRepository{MockRepository: &MockRepository[TestModel]{
+		UpsertFn: func(key string, item TestModel) (TestModel, error) {
+			return item, nil
+		},
+	}}
+	service := NewGenericService[TestModel](repo)
 
-	err := service.Update("01JW4MH8S671QVVGD0NYY1XWAP", ctx)
+	_, created, err := service.Upsert(t.Context(), "id", "01JW4MH8S671QVVGD0NYY1XWAP", TestModel{Name: "Restored"})
 
 	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, softdelete.WithDeleted, repo.scope)
+}
+
+func TestGenericService_UpsertInvalid(t *testing.T) {
+	service := NewGenericService[TestModel](&MockRepository[TestModel]{})
+
+	_, _, err := service.Upsert(t.Context(), "id", "01JW4MH8S671QVVGD0NYY1XWAP", TestModel{Age: -1})
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+
+	_, _, err = service.Upsert(t.Context(), "id", "ext-42", TestModel{Name: "New"})
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+}
+
+func TestGenericService_PatchVersionMismatch(t *testing.T) {
+	type versioned struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		Version int64  `json:"version"`
+	}
+
+	mockRepo := &MockRepository[versioned]{
+		FindByIDFn: func(id string, fields []string) (versioned, error) {
+			return versioned{ID: id, Name: "Name", Version: 2}, nil
+		},
+	}
+	service := NewGenericService[versioned](mockRepo)
+
+	// Even a patch without changes must fail on a stale version.
+	ctx := versioning.WithExpected(t.Context(), []int64{1})
+	_, err := service.Patch(ctx, "01JW4MH8S671QVVGD0NYY1XWAP", MergePatch, []byte(`{"name":"Name"}`))
+
+	assert.ErrorIs(t, err, apperror.ErrPreconditionFailed)
 }
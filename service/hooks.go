@@ -0,0 +1,142 @@
+package service
+
+import "context"
+
+// Models can implement any of these interfaces, with pointer receivers, to
+// run code around GenericServiceImpl operations. A hook may modify the
+// entity; returning an error aborts the operation with that error, so hooks
+// should return apperror values to control the response status.
+type (
+	BeforeCreator interface {
+		BeforeCreate(ctx context.Context) error
+	}
+	AfterCreator interface {
+		AfterCreate(ctx context.Context) error
+	}
+	BeforeUpdater interface {
+		BeforeUpdate(ctx context.Context) error
+	}
+	AfterUpdater interface {
+		AfterUpdate(ctx context.Context) error
+	}
+	BeforeDeleter interface {
+		BeforeDelete(ctx context.Context) error
+	}
+	AfterDeleter interface {
+		AfterDelete(ctx context.Context) error
+	}
+)
+
+// HookFunc receives the id of the entity (empty before it is created) and
+// the entity itself.
+type HookFunc[T any] func(ctx context.Context, id string, item *T) error
+
+// Hooks registers lifecycle callbacks without changing the model type. They
+// run after the model's own hooks, in registration order.
+//
+// Before hooks run after validation, so they can store values that would not
+// pass it (e.g. a password hash). Delete hooks receive the stored entity.
+type Hooks[T any] struct {
+	BeforeCreate HookFunc[T]
+	AfterCreate  HookFunc[T]
+	BeforeUpdate HookFunc[T]
+	AfterUpdate  HookFunc[T]
+	BeforeDelete HookFunc[T]
+	AfterDelete  HookFunc[T]
+}
+
+type event int
+
+const (
+	beforeCreate event = iota
+	afterCreate
+	beforeUpdate
+	afterUpdate
+	beforeDelete
+	afterDelete
+)
+
+func (h Hooks[T]) get(e event) HookFunc[T] {
+	switch e {
+	case beforeCreate:
+		return h.BeforeCreate
+	case afterCreate:
+		return h.AfterCreate
+	case beforeUpdate:
+		return h.BeforeUpdate
+	case afterUpdate:
+		return h.AfterUpdate
+	case beforeDelete:
+		return h.BeforeDelete
+	case afterDelete:
+		return h.AfterDelete
+	}
+
+	return nil
+}
+
+func modelHook(ctx context.Context, e event, item any) error {
+	switch e {
+	case beforeCreate:
+		if h, ok := item.(BeforeCreator); ok {
+			return h.BeforeCreate(ctx)
+		}
+	case afterCreate:
+		if h, ok := item.(AfterCreator); ok {
+			return h.AfterCreate(ctx)
+		}
+	case beforeUpdate:
+		if h, ok := item.(BeforeUpdater); ok {
+			return h.BeforeUpdate(ctx)
+		}
+	case afterUpdate:
+		if h, ok := item.(AfterUpdater); ok {
+			return h.AfterUpdate(ctx)
+		}
+	case beforeDelete:
+		if h, ok := item.(BeforeDeleter); ok {
+			return h.BeforeDelete(ctx)
+		}
+	case afterDelete:
+		if h, ok := item.(AfterDeleter); ok {
+			return h.AfterDelete(ctx)
+		}
+	}
+
+	return nil
+}
+
+func (s *GenericServiceImpl[T]) runHooks(ctx context.Context, e event, id string, item *T) error {
+	if err := modelHook(ctx, e, item); err != nil {
+		return err
+	}
+
+	for _, h := range s.Hooks {
+		if fn := h.get(e); fn != nil {
+			if err := fn(ctx, id, item); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasDeleteHooks reports whether Delete must load the entity for its hooks.
+func (s *GenericServiceImpl[T]) hasDeleteHooks() bool {
+	var item T
+	if _, ok := any(&item).(BeforeDeleter); ok {
+		return true
+	}
+	if _, ok := any(&item).(AfterDeleter); ok {
+		return true
+	}
+
+	for _, h := range s.Hooks {
+		if h.BeforeDelete != nil || h.AfterDelete != nil {
+			return true
+		}
+	}
+
+	return false
+}
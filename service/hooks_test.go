@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"api_boilerplate/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type hookedModel struct {
+	ID       string `json:"id"`
+	Name     string `json:"name" validate:"required"`
+	Password string `json:"password"`
+}
+
+func (m *hookedModel) BeforeCreate(ctx context.Context) error {
+	m.Password = "hashed:" + m.Password
+	return nil
+}
+
+func (m *hookedModel) BeforeDelete(ctx context.Context) error {
+	if m.Name == "admin" {
+		return apperror.Forbidden(nil, "admin cannot be deleted")
+	}
+
+	return nil
+}
+
+func TestHooks_ModelBeforeCreate(t *testing.T) {
+	repo := &MockRepository[hookedModel]{
+		CreateFn: func(item hookedModel) (hookedModel, error) {
+			assert.Equal(t, "hashed:secret", item.Password)
+			item.ID = "01JW4MH8S671QVVGD0NYY1XWAP"
+			return item, nil
+		},
+	}
+
+	var afterID string
+	service := NewGenericService(repo, Hooks[hookedModel]{
+		AfterCreate: func(ctx context.Context, id string, item *hookedModel) error {
+			afterID = id
+			item.Password = ""
+			return nil
+		},
+	})
+
+	created, err := service.Create(t.Context(), hookedModel{Name: "john", Password: "secret"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", afterID)
+	assert.Empty(t, created.Password)
+}
+
+func TestHooks_AbortCreate(t *testing.T) {
+	service := NewGenericService(&MockRepository[hookedModel]{}, Hooks[hookedModel]{
+		BeforeCreate: func(ctx context.Context, id string, item *hookedModel) error {
+			return apperror.Conflict(nil, "name reserved")
+		},
+	})
+
+	_, err := service.Create(t.Context(), hookedModel{Name: "john"})
+
+	assert.ErrorIs(t, err, apperror.ErrConflict)
+}
+
+func TestHooks_BeforeUpdate(t *testing.T) {
+	updated := false
+	repo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return TestModel{ID: id, Name: "old"}, nil
+		},
+		UpdateFn: func(id string, item TestModel) error {
+			updated = true
+			assert.Equal(t, "NEW", item.Name)
+			return nil
+		},
+	}
+	service := NewGenericService(repo, Hooks[TestModel]{
+		BeforeUpdate: func(ctx context.Context, id string, item *TestModel) error {
+			assert.Equal(t, "01JW4MH8S671QVVGD0NYY1XWAP", id)
+			item.Name = strings.ToUpper(item.Name)
+			return nil
+		},
+	})
+
+	_, err := service.Update(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP", map[string]interface{}{"name": "new"})
+
+	assert.NoError(t, err)
+	assert.True(t, updated)
+}
+
+func TestHooks_BeforeUpdatePatchChanges(t *testing.T) {
+	stored := TestModel{ID: "01JW4MH8S671QVVGD0NYY1XWAP", Name: "old", Age: 30}
+	repo := &MockRepository[TestModel]{
+		FindByIDFn: func(id string, fields []string) (TestModel, error) {
+			return stored, nil
+		},
+		PatchFn: func(id string, changes map[string]interface{}) error {
+			assert.Equal(t, map[string]interface{}{"name": "NEW", "age": float64(31)}, changes)
+			return nil
+		},
+	}
+	service := NewGenericService(repo, Hooks[TestModel]{
+		BeforeUpdate: func(ctx context.Context, id string, item *TestModel) error {
+			item.Name = strings.ToUpper(item.Name)
+			item.Age++
+			return nil
+		},
+	})
+
+	_, err := service.Patch(t.Context(), stored.ID, MergePatch, []byte(`{"name":"new"}`))
+
+	assert.NoError(t, err)
+}
+
+func TestHooks_Delete(t *testing.T) {
+	deleted := false
+	repo := &MockRepository[hookedModel]{
+		FindByIDFn: func(id string, fields []string) (hookedModel, error) {
+			return hookedModel{ID: id, Name: id}, nil
+		},
+		DeleteFn: func(id string) error {
+			deleted = true
+			return nil
+		},
+	}
+
+	var after *hookedModel
+	service := NewGenericService(repo, Hooks[hookedModel]{
+		AfterDelete: func(ctx context.Context, id string, item *hookedModel) error {
+			after = item
+			return nil
+		},
+	})
+
+	err := service.Delete(t.Context(), "admin")
+	assert.ErrorIs(t, err, apperror.ErrForbidden)
+	assert.False(t, deleted)
+
+	err = service.Delete(t.Context(), "john")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.Equal(t, "john", after.Name)
+}
+
+func TestHooks_DeleteWithoutHooksSkipsLookup(t *testing.T) {
+	repo := &MockRepository[TestModel]{
+		DeleteFn: func(id string) error { return nil },
+	}
+
+	assert.NoError(t, NewGenericService[TestModel](repo).Delete(t.Context(), "01JW4MH8S671QVVGD0NYY1XWAP"))
+}
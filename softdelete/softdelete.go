@@ -0,0 +1,78 @@
+package softdelete
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+)
+
+// Column holds the deletion timestamp of soft-deleted rows.
+const Column = "deleted_at"
+
+var (
+	ErrInvalidFlag = errors.New("with_deleted and only_deleted must be true or false")
+	ErrConflicting = errors.New("with_deleted and only_deleted cannot be used together")
+)
+
+// Scope selects which rows of a soft-delete resource a query sees.
+type Scope int
+
+const (
+	// Active hides deleted rows; it is the default.
+	Active Scope = iota
+	// WithDeleted shows deleted and active rows.
+	WithDeleted
+	// OnlyDeleted shows only deleted rows.
+	OnlyDeleted
+)
+
+// Parse reads the with_deleted and only_deleted query parameters.
+func Parse(values url.Values) (Scope, error) {
+	withDeleted, err := flag(values, "with_deleted")
+	if err != nil {
+		return Active, err
+	}
+
+	onlyDeleted, err := flag(values, "only_deleted")
+	if err != nil {
+		return Active, err
+	}
+
+	switch {
+	case withDeleted && onlyDeleted:
+		return Active, ErrConflicting
+	case withDeleted:
+		return WithDeleted, nil
+	case onlyDeleted:
+		return OnlyDeleted, nil
+	default:
+		return Active, nil
+	}
+}
+
+func flag(values url.Values, name string) (bool, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, ErrInvalidFlag
+	}
+
+	return value, nil
+}
+
+type scopeKey struct{}
+
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeKey{}, scope)
+}
+
+// FromContext returns the scope set by WithScope, or Active.
+func FromContext(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeKey{}).(Scope)
+	return scope
+}
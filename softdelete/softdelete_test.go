@@ -0,0 +1,37 @@
+package softdelete
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		scope Scope
+		err   error
+	}{
+		{"", Active, nil},
+		{"with_deleted=true", WithDeleted, nil},
+		{"only_deleted=1", OnlyDeleted, nil},
+		{"with_deleted=false", Active, nil},
+		{"with_deleted=yes", Active, ErrInvalidFlag},
+		{"with_deleted=true&only_deleted=true", Active, ErrConflicting},
+	}
+
+	for _, tt := range tests {
+		values, _ := url.ParseQuery(tt.query)
+
+		scope, err := Parse(values)
+
+		assert.Equal(t, tt.scope, scope, tt.query)
+		assert.ErrorIs(t, err, tt.err, tt.query)
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	assert.Equal(t, Active, FromContext(t.Context()))
+	assert.Equal(t, OnlyDeleted, FromContext(WithScope(t.Context(), OnlyDeleted)))
+}
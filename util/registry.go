@@ -1,24 +1,148 @@
 package util
 
 import (
+	"fmt"
+	"slices"
+	"time"
+
+	"api_boilerplate/config"
 	"api_boilerplate/controller"
 	"api_boilerplate/model"
 	"api_boilerplate/repository"
 	"api_boilerplate/service"
 
 	"github.com/gin-gonic/gin"
-	"github.com/jmoiron/sqlx"
 )
 
-func RegisterGenericResource[T any](r *gin.Engine, db *sqlx.DB, path string, fields []string) {
-	repo := repository.NewSqlxRepository[T](db, path, fields)
-	service := service.NewGenericService(repo)
-	controller := controller.NewGenericController(service)
-	controller.RegisterRoutes(r, "/"+path)
+// Option customizes how RegisterGenericResource wires a resource.
+type Option[T any] func(*resourceOptions[T])
+
+type resourceOptions[T any] struct {
+	hooks   []service.Hooks[T]
+	wrap    []func(service.GenericService[T]) service.GenericService[T]
+	routes  []controller.Route
+	extends []func(*gin.RouterGroup, service.GenericService[T])
+
+	upsert     bool
+	upsertKeys []string
+	softDelete bool
+	versioned  bool
+}
+
+// WithHooks registers lifecycle hooks on the generic service.
+func WithHooks[T any](hooks ...service.Hooks[T]) Option[T] {
+	return func(o *resourceOptions[T]) {
+		o.hooks = append(o.hooks, hooks...)
+	}
+}
+
+// WithService replaces the service used by the controller, usually with one
+// that embeds or delegates to the generic service it receives.
+func WithService[T any](wrap func(service.GenericService[T]) service.GenericService[T]) Option[T] {
+	return func(o *resourceOptions[T]) {
+		o.wrap = append(o.wrap, wrap)
+	}
+}
+
+// WithRoutes exposes only the given generic endpoints.
+func WithRoutes[T any](routes ...controller.Route) Option[T] {
+	return func(o *resourceOptions[T]) {
+		o.routes = routes
+	}
+}
+
+// ReadOnly exposes only the list and get endpoints.
+func ReadOnly[T any]() Option[T] {
+	return WithRoutes[T](controller.RouteList, controller.RouteGet)
+}
+
+// WithUpsert makes PUT /:id create missing entities and adds
+// PUT /by-<key>/:value for each natural key, which must be a field with a
+// unique index. Both replace the whole entity, omitted fields included.
+func WithUpsert[T any](keys ...string) Option[T] {
+	return func(o *resourceOptions[T]) {
+		o.upsert = true
+		o.upsertKeys = append(o.upsertKeys, keys...)
+	}
+}
+
+// WithSoftDelete makes DELETE stamp a deleted_at column, which the model
+// must have (outside of fields), and adds the restore and purge endpoints.
+func WithSoftDelete[T any]() Option[T] {
+	return func(o *resourceOptions[T]) {
+		o.softDelete = true
+	}
+}
+
+// WithVersioning enables optimistic concurrency on a version column, which
+// the model must have (outside of fields): writes increment it, GET returns
+// it as an ETag and If-Match guards PUT, PATCH and DELETE.
+func WithVersioning[T any]() Option[T] {
+	return func(o *resourceOptions[T]) {
+		o.versioned = true
+	}
+}
+
+// WithExtraRoutes adds custom endpoints to the resource's route group.
+func WithExtraRoutes[T any](register func(group *gin.RouterGroup, svc service.GenericService[T])) Option[T] {
+	return func(o *resourceOptions[T]) {
+		o.extends = append(o.extends, register)
+	}
+}
+
+func RegisterGenericResource[T any](
+	r *gin.Engine,
+	uow *repository.UnitOfWork,
+	cfg *config.Config,
+	path string,
+	fields []string,
+	opts ...Option[T],
+) {
+	var options resourceOptions[T]
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	for _, key := range options.upsertKeys {
+		if !slices.Contains(fields, key) {
+			panic(fmt.Sprintf("upsert key %q is not a field of %s", key, path))
+		}
+	}
+
+	resource := cfg.Resource(path)
+
+	repo := repository.NewSqlxRepository[T](uow.DB, path, fields)
+	repo.QueryTimeout = time.Duration(resource.QueryTimeout)
+	repo.SoftDelete = options.softDelete
+	repo.Versioned = options.versioned
+	repository.Register(uow, repo)
+
+	var service service.GenericService[T] = &service.GenericServiceImpl[T]{Repo: repo, Hooks: options.hooks, Tx: uow}
+	for _, wrap := range options.wrap {
+		service = wrap(service)
+	}
+
+	controller := controller.NewGenericController(service, fields)
+	controller.Limits = resource.Limits()
+	controller.Dialect = repo.Dialect
+	controller.Routes = options.routes
+	controller.MaxBulkItems = resource.MaxBulkItems
+	controller.Upsert = options.upsert
+	controller.UpsertKeys = options.upsertKeys
+	controller.SoftDelete = options.softDelete
+	controller.Retention = time.Duration(resource.Retention)
+	controller.AdminToken = cfg.Server.AdminToken
+	controller.Versioned = options.versioned
+	controller.RequireIfMatch = resource.RequireIfMatch
+
+	group := controller.RegisterRoutes(r, "/"+path)
+	for _, extend := range options.extends {
+		extend(group, service)
+	}
 }
 
-func RegisterDomains(r *gin.Engine, db *sqlx.DB) {
-	RegisterGenericResource[model.User](r, db, "user", model.UserFields)
-	RegisterGenericResource[model.Product](r, db, "product", model.ProductFields)
-	RegisterGenericResource[model.Store](r, db, "store", model.StoreFields)
+func RegisterDomains(r *gin.Engine, uow *repository.UnitOfWork, cfg *config.Config) {
+	RegisterGenericResource[model.User](r, uow, cfg, "user", model.UserFields)
+	RegisterGenericResource[model.Product](r, uow, cfg, "product", model.ProductFields)
+	RegisterGenericResource[model.Store](r, uow, cfg, "store", model.StoreFields)
 }
@@ -0,0 +1,128 @@
+package util
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"api_boilerplate/config"
+	"api_boilerplate/repository"
+	"api_boilerplate/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	_ "modernc.org/sqlite"
+)
+
+type item struct {
+	ID   string `db:"id" json:"id"`
+	Name string `db:"name" json:"name"`
+}
+
+// namedService overrides GetByID and delegates everything else.
+type namedService struct {
+	service.GenericService[item]
+}
+
+func (s namedService) GetByID(ctx context.Context, id string, fields []string) (item, error) {
+	return item{ID: id, Name: "custom"}, nil
+}
+
+func setup(t *testing.T, opts ...Option[item]) *gin.Engine {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterGenericResource(r, repository.NewUnitOfWork(db), config.Default(), "item", []string{"id", "name"}, opts...)
+
+	return r
+}
+
+func request(r *gin.Engine, method string, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestRegisterReadOnly(t *testing.T) {
+	r := setup(t, ReadOnly[item](), WithService(func(s service.GenericService[item]) service.GenericService[item] {
+		return namedService{s}
+	}))
+
+	w := request(r, http.MethodGet, "/item/01JW4MH8S671QVVGD0NYY1XWAP")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id":"01JW4MH8S671QVVGD0NYY1XWAP","name":"custom"}`, w.Body.String())
+
+	for _, method := range []string{http.MethodPut, http.MethodPatch, http.MethodDelete} {
+		assert.Equal(t, http.StatusNotFound, request(r, method, "/item/01JW4MH8S671QVVGD0NYY1XWAP").Code, method)
+	}
+	assert.Equal(t, http.StatusNotFound, request(r, http.MethodPost, "/item/").Code)
+}
+
+func TestRegisterExtraRoutes(t *testing.T) {
+	r := setup(t, WithExtraRoutes(func(group *gin.RouterGroup, svc service.GenericService[item]) {
+		group.GET("/:id/name", func(ctx *gin.Context) {
+			ctx.String(http.StatusOK, "extra "+ctx.Param("id"))
+		})
+	}))
+
+	w := request(r, http.MethodGet, "/item/42/name")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "extra 42", w.Body.String())
+}
+
+func TestRegisterBulk(t *testing.T) {
+	db, err := sqlx.Open("sqlite", ":memory:")
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+
+	db.SetMaxOpenConns(1)
+	db.MustExec(`CREATE TABLE "item" ("id" TEXT PRIMARY KEY, "name" TEXT NOT NULL UNIQUE, "created_at" TEXT, "updated_at" TEXT)`)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	RegisterGenericResource[item](r, repository.NewUnitOfWork(db), config.Default(), "item", []string{"id", "name"})
+
+	create := func(query string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/item/bulk"+query, strings.NewReader(body)))
+		return w
+	}
+
+	// The duplicate rolls back the whole atomic request.
+	w := create("", `[{"name":"a"},{"name":"b"},{"name":"a"}]`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	var total int
+	require.NoError(t, db.Get(&total, `SELECT COUNT(*) FROM "item"`))
+	assert.Zero(t, total)
+
+	// In partial mode only the duplicate is rejected.
+	w = create("?mode=partial", `[{"name":"a"},{"name":"b"},{"name":"a"}]`)
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+
+	var body struct {
+		Succeeded int `json:"succeeded"`
+		Failed    int `json:"failed"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, 2, body.Succeeded)
+	assert.Equal(t, 1, body.Failed)
+
+	require.NoError(t, db.Get(&total, `SELECT COUNT(*) FROM "item"`))
+	assert.Equal(t, 2, total)
+}
+
+func TestRegisterUpsertUnknownKey(t *testing.T) {
+	assert.Panics(t, func() {
+		setup(t, WithUpsert[item]("email"))
+	})
+}
@@ -0,0 +1,125 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"api_boilerplate/apperror"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate = newValidator()
+	patterns sync.Map
+)
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Report fields by their JSON name, which is what clients send.
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+
+		return name
+	})
+
+	// regex=^[a-z]+$ matches the field against a pattern. Patterns cannot
+	// contain "," or "|" since those separate validation tags.
+	_ = v.RegisterValidation("regex", func(fl validator.FieldLevel) bool {
+		pattern, err := compile(fl.Param())
+		if err != nil {
+			return false
+		}
+
+		return pattern.MatchString(fl.Field().String())
+	})
+
+	return v
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := patterns.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	patterns.Store(pattern, compiled)
+
+	return compiled, nil
+}
+
+// Validate checks the `validate` struct tags of item, returning an
+// apperror validation error with one entry per rejected field.
+func Validate(item interface{}) error {
+	err := validate.Struct(item)
+	if err == nil {
+		return nil
+	}
+
+	var invalid *validator.InvalidValidationError
+	if errors.As(err, &invalid) {
+		return nil
+	}
+
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return err
+	}
+
+	var fields []apperror.FieldError
+	for _, e := range errs {
+		fields = append(fields, apperror.FieldError{Field: e.Field(), Message: message(e)})
+	}
+
+	return apperror.Validation(err, "request body failed validation").WithFields(fields...)
+}
+
+func message(e validator.FieldError) string {
+	unit := ""
+	if e.Kind() == reflect.String {
+		unit = " characters long"
+	}
+
+	switch e.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email"
+	case "url":
+		return "must be a valid URL"
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", e.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", e.Param(), unit)
+	case "len":
+		return fmt.Sprintf("must have length %s", e.Param())
+	case "gt":
+		return fmt.Sprintf("must be greater than %s", e.Param())
+	case "gte":
+		return fmt.Sprintf("must be greater than or equal to %s", e.Param())
+	case "lt":
+		return fmt.Sprintf("must be less than %s", e.Param())
+	case "lte":
+		return fmt.Sprintf("must be less than or equal to %s", e.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.Join(strings.Fields(e.Param()), ", "))
+	case "regex":
+		return fmt.Sprintf("must match %s", e.Param())
+	default:
+		return fmt.Sprintf("failed on the %q rule", e.Tag())
+	}
+}
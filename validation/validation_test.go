@@ -0,0 +1,51 @@
+package validation
+
+import (
+	"testing"
+
+	"api_boilerplate/apperror"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testUser struct {
+	ID    string `json:"id"`
+	Name  string `json:"name" validate:"required,min=2,max=5"`
+	Email string `json:"email" validate:"required,email"`
+	Age   int    `json:"age" validate:"gte=0,lte=150"`
+	Role  string `json:"role" validate:"omitempty,oneof=admin user"`
+	Code  string `json:"code" validate:"omitempty,regex=^[A-Z]{3}$"`
+}
+
+func TestValidate(t *testing.T) {
+	err := Validate(testUser{Name: "John", Email: "john@mail.com", Age: 30, Role: "admin", Code: "ABC"})
+
+	assert.NoError(t, err)
+}
+
+func TestValidateFieldErrors(t *testing.T) {
+	err := Validate(testUser{Name: "J", Email: "not-an-email", Age: -1, Role: "root", Code: "abc"})
+
+	assert.ErrorIs(t, err, apperror.ErrValidation)
+
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "name", Message: "must be at least 2 characters long"},
+		{Field: "email", Message: "must be a valid email"},
+		{Field: "age", Message: "must be greater than or equal to 0"},
+		{Field: "role", Message: "must be one of: admin, user"},
+		{Field: "code", Message: "must match ^[A-Z]{3}$"},
+	}, appErr.Fields)
+}
+
+func TestValidateRequired(t *testing.T) {
+	err := Validate(testUser{})
+
+	var appErr *apperror.Error
+	assert.ErrorAs(t, err, &appErr)
+	assert.Equal(t, []apperror.FieldError{
+		{Field: "name", Message: "is required"},
+		{Field: "email", Message: "is required"},
+	}, appErr.Fields)
+}
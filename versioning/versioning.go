@@ -0,0 +1,76 @@
+package versioning
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Column holds the version of a row, incremented on every update.
+const Column = "version"
+
+var ErrInvalidETag = errors.New("If-Match must be * or a list of entity tags")
+
+// ETag renders version as a strong entity tag.
+func ETag(version int64) string {
+	return strconv.Quote(strconv.FormatInt(version, 10))
+}
+
+// ParseIfMatch reads an If-Match header into the versions it accepts. any
+// is set for "*", which matches every version. If-Match uses the strong
+// comparison, so weak tags and tags that are not versions match nothing.
+func ParseIfMatch(header string) (versions []int64, any bool, err error) {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return nil, true, nil
+	}
+
+	versions = []int64{}
+	for rest := header; rest != ""; {
+		weak := strings.HasPrefix(rest, "W/")
+		if weak {
+			rest = rest[2:]
+		}
+
+		if !strings.HasPrefix(rest, `"`) {
+			return nil, false, ErrInvalidETag
+		}
+
+		end := strings.IndexByte(rest[1:], '"')
+		if end < 0 {
+			return nil, false, ErrInvalidETag
+		}
+
+		tag := rest[1 : end+1]
+		rest = strings.TrimLeft(rest[end+2:], " \t")
+
+		if rest != "" {
+			if rest[0] != ',' {
+				return nil, false, ErrInvalidETag
+			}
+			rest = strings.TrimLeft(rest[1:], " \t")
+		}
+
+		if version, err := strconv.ParseInt(tag, 10, 64); err == nil && !weak {
+			versions = append(versions, version)
+		}
+	}
+
+	return versions, false, nil
+}
+
+type expectedKey struct{}
+
+// WithExpected makes writes through the context fail unless the row is
+// still at one of versions.
+func WithExpected(ctx context.Context, versions []int64) context.Context {
+	return context.WithValue(ctx, expectedKey{}, versions)
+}
+
+// Expected returns the versions set by WithExpected, which may be empty
+// when no tag could match.
+func Expected(ctx context.Context) ([]int64, bool) {
+	versions, ok := ctx.Value(expectedKey{}).([]int64)
+	return versions, ok
+}
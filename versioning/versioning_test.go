@@ -0,0 +1,48 @@
+package versioning
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseIfMatch(t *testing.T) {
+	tests := []struct {
+		header   string
+		versions []int64
+	}{
+		{ETag(7), []int64{7}},
+		{`"1", "2"`, []int64{1, 2}},
+		{`W/"3", "4"`, []int64{4}},
+		{`W/"3"`, []int64{}},
+		{`"a"`, []int64{}},
+		{`"a,b",  "5"`, []int64{5}},
+	}
+
+	for _, tt := range tests {
+		versions, any, err := ParseIfMatch(tt.header)
+
+		require.NoError(t, err, tt.header)
+		assert.False(t, any, tt.header)
+		assert.Equal(t, tt.versions, versions, tt.header)
+	}
+
+	_, any, err := ParseIfMatch("*")
+	assert.NoError(t, err)
+	assert.True(t, any)
+
+	for _, header := range []string{`7`, `"7`, `"1" "2"`, "`7`"} {
+		_, _, err := ParseIfMatch(header)
+		assert.ErrorIs(t, err, ErrInvalidETag, header)
+	}
+}
+
+func TestExpected(t *testing.T) {
+	_, ok := Expected(t.Context())
+	assert.False(t, ok)
+
+	versions, ok := Expected(WithExpected(t.Context(), []int64{3}))
+	assert.True(t, ok)
+	assert.Equal(t, []int64{3}, versions)
+}